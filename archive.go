@@ -0,0 +1,129 @@
+package main
+
+import (
+	"time"
+
+	"main/internal/archive"
+	"main/internal/config"
+	"main/internal/tools"
+)
+
+// logFileNameFor returns where the logs are saved, a segmented archive takes the place of the log file, which a sync always keeps.
+// A compressed log file gets the extension of its codec, the segments of an archive get it instead
+func logFileNameFor(logFileName string) string {
+	if config.Railway.Segmented.Bool() || config.Command == config.CommandSync {
+		return archive.PathFor(logFileName)
+	}
+
+	if logFileName == config.OutputStdout {
+		return logFileName
+	}
+
+	return config.Railway.Compress.Codec().Named(logFileName)
+}
+
+// openArchive opens the archive that the logs are saved to, new segments are compressed with --compress
+func openArchive(logFileName string) (*archive.Archive, error) {
+	logArchive, err := archive.Open(logFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	logArchive.Compression = config.Railway.Compress.Codec()
+
+	return logArchive, nil
+}
+
+// readOldestLog returns the timestamp of the oldest log in the log file or archive and the number of logs at it
+func readOldestLog(logFileName string) (time.Time, int, error) {
+	if archive.Named(logFileName) {
+		logArchive, err := archive.Open(logFileName)
+		if err != nil {
+			return time.Time{}, 0, err
+		}
+
+		oldest, atOldest := logArchive.Oldest()
+
+		return oldest, atOldest, nil
+	}
+
+	oldest, err := tools.ReadFirstLineTimestamp(logFileName)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+
+	atOldest, err := tools.CountFirstTimestampLines(logFileName)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+
+	return oldest, atOldest, nil
+}
+
+// readNewestLog returns the timestamp of the newest log in the log file or archive and the number of logs at it
+func readNewestLog(logFileName string) (time.Time, int, error) {
+	if archive.Named(logFileName) {
+		logArchive, err := archive.Open(logFileName)
+		if err != nil {
+			return time.Time{}, 0, err
+		}
+
+		newest := logArchive.Newest()
+
+		atNewest, err := logArchive.LogsAt(newest)
+		if err != nil {
+			return time.Time{}, 0, err
+		}
+
+		return newest, atNewest, nil
+	}
+
+	newest, err := tools.ReadLastLineTimestamp(logFileName)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+
+	atNewest, err := tools.CountLastTimestampLines(logFileName)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+
+	return newest, atNewest, nil
+}
+
+// countLogs returns the number of logs in the log file or archive
+func countLogs(logFileName string) (int64, error) {
+	if archive.Named(logFileName) {
+		logArchive, err := archive.Open(logFileName)
+		if err != nil {
+			return 0, err
+		}
+
+		return logArchive.Lines(), nil
+	}
+
+	return tools.CountLines(logFileName)
+}
+
+// seedResumedArchive remembers the logs at the oldest timestamp of an archive that is resumed, the previous log file is
+// de-duplicated while it is merged back in but the segments of an archive are never read again
+func seedResumedArchive(target logTarget) error {
+	logArchive, err := archive.Open(target.logFileName)
+	if err != nil {
+		return err
+	}
+
+	oldest, _ := logArchive.Oldest()
+
+	for i, segment := range logArchive.Index.Segments {
+		if !segment.Oldest.Equal(oldest) {
+			continue
+		}
+
+		if err := target.deduplicator.SeedFirstTimestamp(logArchive.SegmentPaths()[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"main/internal/config"
+	"main/internal/railway"
+)
+
+// authenticate creates the railway client for the token that was provided, account and api tokens can be
+// any kind of token so their kind is detected
+func authenticate() *railway.RailwayClient {
+	ctx := context.Background()
+
+	endpoint := config.Railway.Endpoint.String()
+
+	credentials := railway.Credentials{}
+
+	switch {
+	case config.Railway.ProjectToken != "":
+		credentials = railway.Credentials{Token: config.Railway.ProjectToken.String(), Kind: railway.TokenKindProject}
+	case config.Railway.TeamToken != "":
+		credentials = railway.Credentials{Token: config.Railway.TeamToken.String(), Kind: railway.TokenKindTeam}
+	case config.Railway.TokenSource == config.TokenSourceCLIConfig:
+		// the Railway CLI is logged in as a user
+		credentials = railway.Credentials{Token: config.Railway.AccountToken.String(), Kind: railway.TokenKindAccount}
+	case config.Railway.AccountToken != "":
+		tokenKind, err := railway.DetectTokenKind(ctx, endpoint, config.Railway.AccountToken.String())
+		if err != nil {
+			fmt.Fprintf(console, "Error: %s\n", strings.TrimSpace(err.Error()))
+			os.Exit(1)
+		}
+
+		credentials = railway.Credentials{Token: config.Railway.AccountToken.String(), Kind: tokenKind}
+	}
+
+	switch {
+	case config.Railway.TokenSource == config.TokenSourceCLIConfig:
+		fmt.Fprintf(console, "Using the token the Railway CLI is logged in with from %s\n", config.Railway.CLIConfigPath)
+	case config.Railway.TokenSource != "":
+		fmt.Fprintf(console, "Using the %s token from %s\n", credentials.Kind, config.Railway.TokenSource)
+	}
+
+	if config.Railway.LinkedPath != "" {
+		fmt.Fprintf(console, "Using the project linked to %s in %s\n", config.Railway.LinkedPath, config.Railway.CLIConfigPath)
+	}
+
+	return railway.NewAuthedClient(endpoint, credentials)
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"main/internal/archive"
+	"main/internal/checkpoint"
+	"main/internal/config"
+	"main/internal/manifest"
+	"main/internal/railway"
+	"main/internal/tools"
+
+	"github.com/dustin/go-humanize"
+)
+
+// checkpointMode returns how the logs of the download end up in the log file
+func checkpointMode(resume bool, catchUp bool) checkpoint.Mode {
+	switch {
+	case catchUp:
+		return checkpoint.ModeCatchUp
+	case resume:
+		return checkpoint.ModeResume
+	default:
+		return checkpoint.ModeDownload
+	}
+}
+
+// loadCheckpoint reads the checkpoint of an interrupted download in the tmp path, nil when there is none.
+// A checkpoint of a download with other parameters can't be continued, it is an error unless --continue is false
+func loadCheckpoint(tmpPath string, logFileName string, query manifest.Query, mode checkpoint.Mode) (*checkpoint.Checkpoint, error) {
+	interrupted, err := checkpoint.Load(tmpPath)
+	if err != nil || interrupted == nil {
+		return nil, err
+	}
+
+	// the logs were saved before the work directory could be removed, there is nothing left to continue
+	if interrupted.Saved {
+		return nil, nil
+	}
+
+	if err := interrupted.Verify(logFileName, query, mode, logFileSize(logFileName)); err != nil {
+		// the interrupted download is thrown away anyway
+		if config.Railway.Continue != "" && !config.Railway.Continue.Bool() {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return interrupted, nil
+}
+
+// askToContinue decides if the interrupted downloads are continued, from --continue or by asking when it isn't set,
+// without a terminal to ask on the flag has to be set
+func askToContinue(descriptions []string) bool {
+	if config.Railway.Continue != "" {
+		return config.Railway.Continue.Bool()
+	}
+
+	for _, description := range descriptions {
+		fmt.Fprintf(console, "Found an interrupted download of %s\n", description)
+	}
+
+	if stat, err := os.Stdin.Stat(); err != nil || (stat.Mode()&os.ModeCharDevice) == 0 {
+		exitWithoutAnswer()
+	}
+
+	fmt.Fprint(console, "Continue where it stopped? [Y/n] ")
+
+	// a character device like /dev/null can't be asked either
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		fmt.Fprintln(console)
+		exitWithoutAnswer()
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "", "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// exitWithoutAnswer exits when there is no one to ask if the interrupted downloads should be continued
+func exitWithoutAnswer() {
+	fmt.Fprintln(console, "Use --continue true to continue it, or --continue false to throw it away and start over")
+	os.Exit(1)
+}
+
+// describeCheckpoint describes an interrupted download for the user
+func describeCheckpoint(interrupted *checkpoint.Checkpoint) string {
+	return fmt.Sprintf("%s started %s with %s logs downloaded",
+		interrupted.Session.LogFileName,
+		formatPosition(interrupted.Session.StartedAt),
+		humanize.Comma(interrupted.Logs()),
+	)
+}
+
+// startCheckpoint starts the journal of the target. The interrupted download is continued from the chunk files it recorded,
+// without one the tmp path is cleared and a new journal is started
+func startCheckpoint(target *logTarget, query manifest.Query, mode checkpoint.Mode, interrupted *checkpoint.Checkpoint) error {
+	// the segments of an archive aren't merged back in, so the logs it has at its oldest timestamp are de-duplicated up front
+	if mode == checkpoint.ModeResume && archive.Named(target.logFileName) {
+		if err := seedResumedArchive(*target); err != nil {
+			return err
+		}
+	}
+
+	if interrupted == nil {
+		if err := tools.ClearTempLogFiles(target.tmpPath); err != nil {
+			return err
+		}
+
+		journal, err := checkpoint.Create(target.tmpPath, checkpoint.NewSession(target.logFileName, query, mode, logFileSize(target.logFileName), target.options))
+		if err != nil {
+			return err
+		}
+
+		target.journal = journal
+
+		return nil
+	}
+
+	files := interrupted.Files(target.tmpPath)
+
+	// chunk files that were written after the last event of the journal are downloaded again
+	if err := tools.ClearTempLogFiles(target.tmpPath, files...); err != nil {
+		return err
+	}
+
+	// the logs that are already in the chunk files aren't written again
+	for _, file := range files {
+		if err := target.deduplicator.Seed(file); err != nil {
+			return err
+		}
+	}
+
+	journal, err := checkpoint.Open(target.tmpPath)
+	if err != nil {
+		return err
+	}
+
+	target.journal = journal
+	target.options = interrupted.Continue(target.options)
+	target.savedLogs = interrupted.Logs()
+	target.savedChunk = interrupted.LastSequence()
+
+	// every log was downloaded before, only saving them is left
+	if interrupted.Done {
+		target.getAllLogs = alreadyCollected
+	}
+
+	return nil
+}
+
+// recoverLogFile undoes a save of the log file that was cut off, returning true when it had to
+func recoverLogFile(tmpPath string, logFileName string) (bool, error) {
+	if archive.Named(logFileName) {
+		return recoverArchive(tmpPath, logFileName)
+	}
+
+	recovered, err := tools.RecoverLogFile(logFileName)
+	if err != nil {
+		return false, err
+	}
+
+	interrupted, err := checkpoint.Load(tmpPath)
+	if err != nil || interrupted == nil {
+		return recovered, err
+	}
+
+	cutOffCatchUp := interrupted.Saving && !interrupted.Saved &&
+		interrupted.Session.Mode == checkpoint.ModeCatchUp &&
+		interrupted.Session.LogFileName == logFileName
+
+	// a catch up appends to the log file, so the logs it appended before it was cut off would be appended twice
+	if cutOffCatchUp && logFileSize(logFileName) > interrupted.Session.LogFileSize {
+		if err := tools.TruncateLogFile(logFileName, interrupted.Session.LogFileSize); err != nil {
+			return recovered, err
+		}
+
+		recovered = true
+	}
+
+	return recovered, nil
+}
+
+// recoverArchive removes the segments a cut off save left behind, the index is replaced in one go so the archive itself
+// is either saved or untouched. A save that replaced the index before it was cut off is done, so the journal is thrown away
+// so it isn't saved again
+func recoverArchive(tmpPath string, logFileName string) (bool, error) {
+	logArchive, err := archive.Open(logFileName)
+	if err != nil {
+		return false, err
+	}
+
+	if err := logArchive.RemoveOrphans(); err != nil {
+		return false, err
+	}
+
+	interrupted, err := checkpoint.Load(tmpPath)
+	if err != nil || interrupted == nil {
+		return false, err
+	}
+
+	cutOffSave := interrupted.Saving && !interrupted.Saved &&
+		interrupted.Session.LogFileName == logFileName
+
+	if cutOffSave && logArchive.Size() != interrupted.Session.LogFileSize {
+		return false, checkpoint.Remove(tmpPath)
+	}
+
+	return false, nil
+}
+
+// saveLogs saves the chunk files of the target to the log file or stdout, appending them when catching up,
+// the journal records the save so one that is cut off can be undone. It returns the summary of the log file
+func saveLogs(target logTarget, useResume bool, catchUp bool) (tools.Summary, error) {
+	if target.journal.UnfinishedWindows() {
+		return tools.Summary{}, checkpoint.ErrUnfinishedWindows
+	}
+
+	if err := target.journal.RecordSaving(); err != nil {
+		return tools.Summary{}, err
+	}
+
+	summary := tools.Summary{}
+
+	var err error
+
+	switch {
+	case target.logFileName == config.OutputStdout:
+		err = writeStdout(target.tmpPath, !catchUp)
+	case archive.Named(target.logFileName):
+		err = saveSegment(target, useResume || catchUp, !catchUp)
+		if err == nil {
+			summary, err = logFileSummary(target.logFileName)
+		}
+	case catchUp:
+		// what the log file held has to be known before the caught up logs are added to it
+		summary, err = logFileSummary(target.logFileName)
+		if err == nil {
+			var appended tools.Summary
+
+			appended, err = tools.FinalLogAppend(target.logFileName, target.tmpPath)
+			summary = summary.Append(appended)
+		}
+	default:
+		summary, err = tools.FinalLogWrite(target.logFileName, target.tmpPath, useResume, target.deduplicator)
+	}
+
+	if err != nil {
+		return tools.Summary{}, err
+	}
+
+	return summary, target.journal.RecordSaved()
+}
+
+// saveSegment saves the chunk files of the target as a new segment of its archive, a download from scratch replaces
+// the segments that were there before
+func saveSegment(target logTarget, add bool, newestFirst bool) error {
+	logArchive, err := openArchive(target.logFileName)
+	if err != nil {
+		return err
+	}
+
+	write := func(output io.Writer) error {
+		return tools.CombineLogFiles(target.tmpPath, output, newestFirst)
+	}
+
+	if add {
+		return logArchive.AddSegment(write)
+	}
+
+	return logArchive.ReplaceSegments(write)
+}
+
+// finishCheckpoint removes the work directory of the target with its journal and chunk files once its logs were saved
+func finishCheckpoint(target logTarget) error {
+	if target.journal != nil {
+		target.journal.Close()
+	}
+
+	return target.workDir.Remove()
+}
+
+// alreadyCollected stands in for the log collection of a download that has every log in its chunk files
+func alreadyCollected(_ context.Context, _ *railway.RailwayClient, _ chan<- railway.LogLinesResponse, _ railway.GetLogsOptions) error {
+	return nil
+}
+
+// logFileSize returns the size of the log file or the segments of the archive, 0 when it doesn't exist yet
+func logFileSize(logFileName string) int64 {
+	if archive.Named(logFileName) {
+		logArchive, err := archive.Open(logFileName)
+		if err != nil {
+			return 0
+		}
+
+		return logArchive.Size()
+	}
+
+	stat, err := os.Stat(logFileName)
+	if err != nil {
+		return 0
+	}
+
+	return stat.Size()
+}
+
+// collectionFinished reports if the log collection found every log, a continued download can find nothing new
+func collectionFinished(collectErr error, downloadedLogs int64) bool {
+	return collectErr == nil || (errors.Is(collectErr, railway.ErrNoLogsFound) && downloadedLogs > 0)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"main/internal/archive"
+	"main/internal/checkpoint"
+	"main/internal/config"
+	"main/internal/manifest"
+	"main/internal/workdir"
+
+	"github.com/dustin/go-humanize"
+)
+
+// runCompact concatenates the segments of an archive into a single log file, the archive itself is left as it is
+func runCompact() {
+	archivePath := filepath.Clean(config.Compact.Archive.String())
+
+	if !archive.Exists(archivePath) {
+		fmt.Fprintf(console, "Error: %s is not a segmented archive\n", archivePath)
+		os.Exit(1)
+	}
+
+	output := config.Compact.Output.String()
+	if output == "" {
+		output = archive.CompactedPath(archivePath)
+	}
+
+	// the log file is compressed with the codec its name ends in
+	output = config.Compact.Compress.Codec().Named(output)
+
+	// a download can't add to the archive while it is compacted
+	workDir, err := workdir.Acquire(config.Compact.WorkDir.String(), archivePath)
+	if err != nil {
+		fmt.Fprintf(console, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	// an interrupted download of the archive is left in the work directory to be continued
+	defer func() {
+		if checkpoint.Exists(workDir.Path) {
+			workDir.Release()
+		} else {
+			workDir.Remove()
+		}
+	}()
+
+	logArchive, err := archive.Open(archivePath)
+	if err != nil {
+		fmt.Fprintf(console, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	if err := logArchive.Compact(output); err != nil {
+		fmt.Fprintf(console, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	// the log file holds the same logs, so it gets the manifest of the archive
+	previous, err := manifest.Read(archivePath)
+	if err != nil {
+		fmt.Fprintf(console, "Error reading manifest: %s\n", err)
+		os.Exit(1)
+	}
+
+	if previous != nil {
+		previous.Lines = logArchive.Lines()
+		previous.UpdatedAt = time.Now().UTC()
+
+		if err := manifest.Write(output, *previous); err != nil {
+			fmt.Fprintf(console, "Error saving manifest: %s\n", err)
+			os.Exit(1)
+		}
+	}
+
+	fmt.Fprintf(console, "Compacted %s logs from %d segments into %s\n", humanize.Comma(logArchive.Lines()), len(logArchive.Index.Segments), output)
+}
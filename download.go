@@ -0,0 +1,269 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"main/internal/checkpoint"
+	"main/internal/compression"
+	"main/internal/config"
+	"main/internal/manifest"
+	"main/internal/railway"
+	"main/internal/tools"
+	"main/internal/workdir"
+)
+
+type getAllLogsFunc func(ctx context.Context, railwayClient *railway.RailwayClient, logs chan<- railway.LogLinesResponse, options railway.GetLogsOptions) error
+
+// logTarget is a single log file and everything needed to download the logs that go into it
+type logTarget struct {
+	logFileName string
+
+	// the chunk files are kept in the tmp path of the work directory, which is locked while the target is downloaded
+	tmpPath string
+	workDir *workdir.WorkDir
+
+	getAllLogs getAllLogsFunc
+	options    railway.GetLogsOptions
+
+	// keep the tags of every log, used when logs from multiple services end up in the same file
+	withTags bool
+
+	// drops the logs that were already written, both while downloading and when the previous log file is merged back in
+	deduplicator *tools.Deduplicator
+
+	// records every chunk file so an interrupted download can be continued
+	journal *checkpoint.Journal
+
+	// the logs and the last chunk file of the interrupted download that is continued
+	savedLogs  int64
+	savedChunk int
+}
+
+// collectLogs downloads the logs of the target into chunk files in the target's tmp path and returns the number of logs collected,
+// onFlush is called with the running total after every chunk that was written to disk
+func collectLogs(ctx context.Context, railwayClient *railway.RailwayClient, target logTarget, onFlush func(downloadedLogs int64, logLines railway.LogLinesResponse)) (int64, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	logLinesChannel := make(chan railway.LogLinesResponse)
+	getAllLogsErrorChannel := make(chan error, 1)
+
+	// a window of a sharded download can finish while its last chunk is still being written,
+	// so finished windows are only recorded in the journal after the chunks that were received before them
+	shardsDone := []int{}
+	shardsDoneMutex := sync.Mutex{}
+
+	recordShardsDone := func() {
+		shardsDoneMutex.Lock()
+		defer shardsDoneMutex.Unlock()
+
+		for _, shard := range shardsDone {
+			target.journal.RecordShardDone(shard)
+		}
+
+		shardsDone = shardsDone[:0]
+	}
+
+	if target.journal != nil {
+		target.options.OnWindows = func(windows []railway.TimeWindow) { target.journal.RecordWindows(windows) }
+		target.options.OnShardDone = func(shard int) {
+			shardsDoneMutex.Lock()
+			defer shardsDoneMutex.Unlock()
+
+			shardsDone = append(shardsDone, shard)
+		}
+	}
+
+	go func() {
+		getAllLogsErrorChannel <- target.getAllLogs(ctx, railwayClient, logLinesChannel, target.options)
+		close(logLinesChannel)
+	}()
+
+	downloadedLogs := target.savedLogs
+	chunks := target.savedChunk
+
+	var flushErr error
+
+	for logLines := range logLinesChannel {
+		// keep draining the channel after a failed flush so the log collection can stop
+		if flushErr != nil {
+			continue
+		}
+
+		chunks++
+
+		chunkFileName := tools.ChunkFileName(target.tmpPath, logLines.OldestLogTimestamp, chunks, chunkCodec())
+
+		flushed, err := flushLogLines(logLines, chunkFileName, target.withTags, target.deduplicator)
+		if err == nil && target.journal != nil {
+			err = recordChunk(target.journal, flushed, chunkFileName, chunks, logLines.Shard)
+		}
+
+		if err != nil {
+			flushErr = err
+
+			cancel() // stop collecting logs that can't be saved
+
+			continue
+		}
+
+		downloadedLogs += int64(flushed.Logs)
+
+		if target.journal != nil {
+			recordShardsDone()
+		}
+
+		if onFlush != nil {
+			onFlush(downloadedLogs, logLines)
+		}
+	}
+
+	if flushErr != nil {
+		return downloadedLogs, flushErr
+	}
+
+	err := <-getAllLogsErrorChannel
+
+	if target.journal != nil {
+		recordShardsDone()
+	}
+
+	// only saving the logs is left, a download that is continued after this doesn't fetch anything
+	if target.journal != nil && ctx.Err() == nil && (err == nil || errors.Is(err, railway.ErrNoLogsFound)) {
+		target.journal.RecordDone()
+	}
+
+	if target.journal != nil && target.journal.Err() != nil {
+		return downloadedLogs, errors.Join(err, target.journal.Err())
+	}
+
+	return downloadedLogs, err
+}
+
+// recordChunk records the chunk file in the journal with the oldest and newest logs that were written to it, and how many share those timestamps
+func recordChunk(journal *checkpoint.Journal, flushed tools.Flushed, chunkFileName string, sequence int, shard int) error {
+	return journal.RecordChunk(checkpoint.Chunk{
+		File:     filepath.Base(chunkFileName),
+		Sequence: sequence,
+		Shard:    shard,
+		Logs:     flushed.Logs,
+		Oldest:   flushed.Oldest,
+		Newest:   flushed.Newest,
+		AtOldest: flushed.AtOldest,
+		AtNewest: flushed.AtNewest,
+	})
+}
+
+// flushLogLines writes a single chunk of logs to the given tmp file with the reconstructor that fits the kind of logs
+func flushLogLines(logLines railway.LogLinesResponse, tmpFileName string, withTags bool, deduplicator *tools.Deduplicator) (tools.Flushed, error) {
+	if logLines.HttpLogs != nil {
+		return tools.FlushHttpLogsToFile(logLines.HttpLogs, tmpFileName, deduplicator)
+	}
+
+	if withTags {
+		return tools.FlushLogsWithTagsToFile(logLines.Logs, tmpFileName, deduplicator)
+	}
+
+	return tools.FlushLogsToFile(logLines.Logs, tmpFileName, deduplicator)
+}
+
+// singleTarget returns the log file of a deployment, service, plugin or environment, the parameters it is downloaded with,
+// and if it holds the logs of the whole environment
+func singleTarget() (string, manifest.Query, bool) {
+	flagName, value := config.Railway.GetOneOfGroupValue("target")
+
+	// without a deployment, service or plugin the logs of the whole environment are downloaded
+	environmentWide := flagName == ""
+
+	if environmentWide {
+		flagName = "environment"
+		value = config.Railway.EnvironmentID.String()
+	}
+
+	// http and build logs are kept apart from the deployment logs of the same deployment
+	switch {
+	case config.Railway.HttpLogs.Bool():
+		flagName = "http"
+	case config.Railway.BuildLogs.Bool():
+		flagName = "build"
+	}
+
+	logFileName := outputFileName(fmt.Sprintf("%s-%s.jsonl", flagName, value), outputValues{
+		kind:        flagName,
+		project:     config.Railway.ProjectID.String(),
+		environment: config.Railway.EnvironmentID.String(),
+		service:     config.Railway.ServiceID.String(),
+		deployment:  config.Railway.DeploymentID.String(),
+		plugin:      config.Railway.PluginID.String(),
+	})
+
+	// the parameters the log file is downloaded with are recorded in its manifest
+	return logFileNameFor(logFileName), downloadQuery(flagName, environmentWide), environmentWide
+}
+
+// singleTargetLogCollection picks the log collection function for the kind of logs requested
+func singleTargetLogCollection() getAllLogsFunc {
+	switch {
+	case config.Railway.CatchUp.Bool() && config.Railway.HttpLogs.Bool():
+		return railway.GetAllHttpLogsCatchUpBlocking
+	case config.Railway.CatchUp.Bool():
+		return railway.GetAllDeploymentLogsCatchUpBlocking
+	case config.Railway.Shards.Int() > 1 && config.Railway.HttpLogs.Bool():
+		return railway.GetAllHttpLogsShardedBlocking
+	case config.Railway.HttpLogs.Bool():
+		return railway.GetAllHttpLogsBlocking
+	case config.Railway.BuildLogs.Bool():
+		return railway.GetAllBuildLogsBlocking
+	case config.Railway.PluginID != "":
+		return railway.GetAllPluginLogsBlocking
+	case config.Railway.Shards.Int() > 1:
+		return railway.GetAllDeploymentLogsShardedBlocking
+	default:
+		return railway.GetAllDeploymentLogsBlocking
+	}
+}
+
+// chunkCodec returns the codec the chunk files are compressed with, they are only compressed with --compress-chunks
+func chunkCodec() compression.Codec {
+	if !config.Railway.CompressChunks.Bool() {
+		return compression.CodecNone
+	}
+
+	return config.Railway.Compress.Codec()
+}
+
+// newDeduplicator creates the deduplicator for a log file from the config
+func newDeduplicator() *tools.Deduplicator {
+	return tools.NewDeduplicator(config.Railway.Dedupe.Bool(), config.Railway.WithID.Bool())
+}
+
+// retryPolicy builds the policy for retrying failed requests from the config
+func retryPolicy() railway.RetryPolicy {
+	return railway.RetryPolicy{
+		MaxAttempts: config.Railway.RetryMaxAttempts.Int(),
+		BaseDelay:   config.Railway.RetryBaseDelay.Duration(),
+		MaxDelay:    config.Railway.RetryMaxDelay.Duration(),
+	}
+}
+
+// formatTimeRange describes the time range set by --since and --until, either end can be open
+func formatTimeRange(since time.Time, until time.Time) string {
+	switch {
+	case since.IsZero():
+		return fmt.Sprintf("until %s", formatPosition(until))
+	case until.IsZero():
+		return fmt.Sprintf("since %s", formatPosition(since))
+	default:
+		return fmt.Sprintf("from %s to %s", formatPosition(since), formatPosition(until))
+	}
+}
+
+// formatPosition formats the timestamp of the oldest downloaded log for progress output
+func formatPosition(timestamp time.Time) string {
+	return timestamp.UTC().Format("January 2, 2006 15:04:05 MST")
+}
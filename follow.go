@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"main/internal/checkpoint"
+	"main/internal/config"
+	"main/internal/manifest"
+	"main/internal/railway"
+	"main/internal/tools"
+
+	"github.com/briandowns/spinner"
+	"github.com/dustin/go-humanize"
+)
+
+// followLogs streams new logs into the log file, or stdout, until interrupted,
+// logs are appended as they arrive so the file stays in chronological order
+func followLogs(railwayClient *railway.RailwayClient, sigChan <-chan os.Signal, logFileName string, query manifest.Query) {
+	toStdout := config.Railway.Stdout.Bool() || logFileName == config.OutputStdout
+	withTags := query.WithTags
+
+	// only the logs from now on are streamed, so a new log file is missing the older logs until it is resumed
+	fileStatus := manifest.StatusIncomplete
+
+	// status messages go to stderr when the logs themselves are written to stdout
+	var status io.Writer = console
+	var output io.Writer = stdout
+
+	if toStdout {
+		status = os.Stderr
+	} else {
+		fileFlags := os.O_APPEND | os.O_CREATE | os.O_WRONLY
+
+		if config.Railway.OverwriteFile.Bool() {
+			fileFlags |= os.O_TRUNC
+		}
+
+		// only one run at a time can write the log file, and a save of it that was cut off is undone first
+		workDir := lockLogFile(logFileName)
+		recoverCutOffSave(workDir.Path, logFileName)
+
+		// streaming doesn't use the work directory, an interrupted download of the log file is left in it to be continued
+		defer func() {
+			if checkpoint.Exists(workDir.Path) {
+				workDir.Release()
+			} else {
+				workDir.Remove()
+			}
+		}()
+
+		// streamed logs are appended to an existing log file, which has to have been downloaded with the same parameters
+		if _, err := os.Stat(logFileName); err == nil && !config.Railway.OverwriteFile.Bool() {
+			previousStatus, err := verifyManifest(logFileName, query)
+			if err != nil {
+				fmt.Fprintf(console, "Error: %s\n", err)
+				fmt.Fprintln(console, "Use the same parameters to continue it, or --overwrite to start over")
+				os.Exit(1)
+			}
+
+			fileStatus = previousStatus
+		}
+
+		// the directories of an --output template are created as needed
+		if err := os.MkdirAll(filepath.Dir(logFileName), 0755); err != nil {
+			fmt.Fprintf(console, "Error creating log file directory: %s\n", err)
+			os.Exit(1)
+		}
+
+		// what the log file already holds, the streamed logs are added to it in the manifest
+		previous := tools.Summary{}
+
+		if !config.Railway.OverwriteFile.Bool() {
+			summary, err := logFileSummary(logFileName)
+			if err != nil {
+				fmt.Fprintf(console, "Error reading log file: %s\n", err)
+				os.Exit(1)
+			}
+
+			previous = summary
+		}
+
+		logFile, err := os.OpenFile(logFileName, fileFlags, 0644)
+		if err != nil {
+			fmt.Fprintf(console, "Error opening log file: %s\n", err)
+			os.Exit(1)
+		}
+
+		defer logFile.Close()
+
+		summaryWriter := tools.NewSummaryWriter(logFile)
+
+		// record what the log file holds once streaming stops
+		defer func() {
+			streamed, err := summaryWriter.Summary()
+			if err == nil {
+				err = saveManifest(logFileName, query, fileStatus, previous.Append(streamed))
+			}
+
+			if err != nil {
+				fmt.Fprintf(console, "Error saving manifest: %s\n", err)
+			}
+		}()
+
+		output = summaryWriter
+	}
+
+	writeLogs := tools.WriteLogs
+
+	// logs from every service end up in the same output, so keep track of where each one came from
+	if withTags {
+		writeLogs = tools.WriteLogsWithTags
+	}
+
+	// the tail already drops the logs a reconnect catches up on twice, the stream is a single source so only the ids are added
+	deduplicator := tools.NewDeduplicator(false, config.Railway.WithID.Bool())
+
+	wsClient := railway.NewAuthedWebSocketClient(railway.WebSocketEndpoint(config.Railway.Endpoint.String()), railwayClient.Credentials())
+
+	// Create context for cancellation
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logLinesChannel := make(chan railway.LogLinesResponse)
+	errorChannel := make(chan error, 1)
+
+	go func() {
+		errorChannel <- railway.TailLogsBlocking(ctx, railwayClient, wsClient, logLinesChannel, railway.GetLogsOptions{
+			DeploymentId:  config.Railway.DeploymentID.String(),
+			EnvironmentId: config.Railway.EnvironmentID.String(),
+			ServiceId:     config.Railway.ServiceID.String(),
+			Filter:        config.Railway.Filter.String(),
+		})
+	}()
+
+	// The spinner would end up in between the logs when they are written to stdout
+	logFollowSpinner := spinner.New(spinner.CharSets[11], (100 * time.Millisecond), spinner.WithWriter(status))
+	logFollowSpinner.Suffix = " 0 Logs"
+	logFollowSpinner.Reverse()
+
+	if !toStdout {
+		logFollowSpinner.Start()
+	}
+
+	fmt.Fprintln(status, "Streaming logs... Press Ctrl / Cmd + C to stop")
+
+	streamedLogs := int64(0)
+
+	for {
+		select {
+		case <-sigChan:
+			logFollowSpinner.Stop()
+
+			fmt.Fprintln(status, "Received interrupt signal, stopping...")
+			fmt.Fprintf(status, "Streamed %s logs\n", humanize.Comma(streamedLogs))
+
+			return
+		case err := <-errorChannel:
+			logFollowSpinner.Stop()
+
+			if err != nil {
+				fmt.Fprintf(status, "Error: %s\n", strings.TrimSpace(err.Error()))
+			}
+
+			fmt.Fprintf(status, "Streamed %s logs\n", humanize.Comma(streamedLogs))
+
+			return
+		case logLines := <-logLinesChannel:
+			writtenLogs, err := writeLogs(output, logLines.Logs, deduplicator)
+			if err != nil {
+				logFollowSpinner.Stop()
+
+				fmt.Fprintf(status, "Error: %s\n", err)
+
+				return
+			}
+
+			streamedLogs += int64(writtenLogs)
+
+			logFollowSpinner.Suffix = fmt.Sprintf(" %s Logs - Latest: %s",
+				humanize.Comma(streamedLogs),
+				formatPosition(latestLogTimestamp(logLines)),
+			)
+		}
+	}
+}
+
+// latestLogTimestamp returns the timestamp of the newest log in a streamed chunk
+func latestLogTimestamp(logLines railway.LogLinesResponse) time.Time {
+	latest := logLines.OldestLogTimestamp
+
+	if len(logLines.Logs) > 0 {
+		if timestamp, err := time.Parse(time.RFC3339Nano, logLines.Logs[len(logLines.Logs)-1].Timestamp); err == nil {
+			latest = timestamp
+		}
+	}
+
+	return latest
+}
@@ -0,0 +1,474 @@
+package archive
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"main/internal/compression"
+	"main/internal/tools"
+)
+
+// EXTENSION is the extension of an archive directory, it takes the place of .jsonl in the name of the log file
+var EXTENSION = ".archive"
+
+// INDEX_FILE_NAME is the index of the segments in an archive directory
+var INDEX_FILE_NAME = "index.json"
+
+// Segment is an immutable file of logs in the archive, ordered oldest first like a log file
+type Segment struct {
+	File string `json:"file"`
+
+	Oldest time.Time `json:"oldest"`
+	Newest time.Time `json:"newest"`
+
+	// AtOldest is the number of logs at the oldest timestamp, resuming skips them when that timestamp is fetched again
+	AtOldest int `json:"atOldest"`
+
+	Lines int64 `json:"lines"`
+	Size  int64 `json:"size"`
+}
+
+// Index lists the segments of an archive oldest first, a segment only belongs to the archive once it is in the index
+type Index struct {
+	Segments []Segment `json:"segments"`
+
+	// Next numbers the next segment so a segment file is never reused
+	Next int `json:"next"`
+
+	// Coverage are the time ranges a sync downloaded every log of, oldest first. Segments that are saved by other downloads
+	// add the time range they downloaded every log of too. Replacing the segments starts the coverage over,
+	// it is left for the next sync to work out from the segments
+	Coverage []Interval `json:"coverage,omitempty"`
+}
+
+// Archive is a log file kept as immutable time ordered segment files and an index, saving logs adds a segment to it
+// instead of rewriting everything that was saved before
+type Archive struct {
+	Path  string
+	Index Index
+
+	// Compression is the codec new segments are written with, the segments that are already saved are read however they were compressed
+	Compression compression.Codec
+}
+
+// PathFor returns the archive directory that takes the place of the log file
+func PathFor(logFileName string) string {
+	return strings.TrimSuffix(logFileName, ".jsonl") + EXTENSION
+}
+
+// Named reports if the path is an archive directory rather than a log file
+func Named(path string) bool {
+	return strings.HasSuffix(path, EXTENSION)
+}
+
+// CompactedPath returns the log file an archive is compacted into by default
+func CompactedPath(path string) string {
+	return strings.TrimSuffix(path, EXTENSION) + ".jsonl"
+}
+
+// Open reads the index of the archive, an archive that doesn't exist yet is empty
+func Open(path string) (*Archive, error) {
+	archive := &Archive{Path: path}
+
+	content, err := os.ReadFile(filepath.Join(path, INDEX_FILE_NAME))
+	if errors.Is(err, os.ErrNotExist) {
+		return archive, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToReadIndex, err)
+	}
+
+	if err := json.Unmarshal(content, &archive.Index); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToParseIndex, err)
+	}
+
+	return archive, nil
+}
+
+// Exists reports if the archive has an index
+func Exists(path string) bool {
+	_, err := os.Stat(filepath.Join(path, INDEX_FILE_NAME))
+
+	return err == nil
+}
+
+// Oldest returns the timestamp of the oldest log in the archive and the number of logs at it
+func (a *Archive) Oldest() (time.Time, int) {
+	if len(a.Index.Segments) == 0 {
+		return time.Time{}, 0
+	}
+
+	oldest := a.Index.Segments[0].Oldest
+	atOldest := 0
+
+	// a burst that was split over more than one save starts more than one segment
+	for _, segment := range a.Index.Segments {
+		if segment.Oldest.Equal(oldest) {
+			atOldest += segment.AtOldest
+		}
+	}
+
+	return oldest, atOldest
+}
+
+// Newest returns the timestamp of the newest log in the archive
+func (a *Archive) Newest() time.Time {
+	newest := time.Time{}
+
+	for _, segment := range a.Index.Segments {
+		if segment.Newest.After(newest) {
+			newest = segment.Newest
+		}
+	}
+
+	return newest
+}
+
+// Lines returns the number of logs in the archive
+func (a *Archive) Lines() int64 {
+	lines := int64(0)
+
+	for _, segment := range a.Index.Segments {
+		lines += segment.Lines
+	}
+
+	return lines
+}
+
+// Size returns the size of the segments in the archive
+func (a *Archive) Size() int64 {
+	size := int64(0)
+
+	for _, segment := range a.Index.Segments {
+		size += segment.Size
+	}
+
+	return size
+}
+
+// SegmentPaths returns the paths of the segments oldest first
+func (a *Archive) SegmentPaths() []string {
+	paths := make([]string, len(a.Index.Segments))
+
+	for i, segment := range a.Index.Segments {
+		paths[i] = filepath.Join(a.Path, segment.File)
+	}
+
+	return paths
+}
+
+// AddSegment writes a new segment with write and adds it to the archive, the segments that are already in the archive are left as they are
+// The segment must have every log from its oldest to its newest one, the archive counts that time range as downloaded.
+// A sharded download that didn't finish every window has gaps between them, so it isn't saved until it is continued
+func (a *Archive) AddSegment(write func(output io.Writer) error) error {
+	return a.saveSegment(write, false, nil)
+}
+
+// SyncSegment writes a new segment with write and adds it to the archive together with the interval it covers,
+// the interval is recorded even when it has no logs
+func (a *Archive) SyncSegment(write func(output io.Writer) error, covered Interval) error {
+	return a.saveSegment(write, false, &covered)
+}
+
+// ReplaceSegments writes a new segment with write and makes it the only segment of the archive, used when the logs are downloaded from scratch
+func (a *Archive) ReplaceSegments(write func(output io.Writer) error) error {
+	return a.saveSegment(write, true, nil)
+}
+
+// saveSegment writes the segment next to its final name and syncs it to disk before it is renamed into place,
+// it only becomes part of the archive once the index that lists it replaced the previous one
+func (a *Archive) saveSegment(write func(output io.Writer) error, replace bool, covered *Interval) error {
+	if err := os.MkdirAll(a.Path, 0755); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToWriteSegment, err)
+	}
+
+	segmentFile := a.Compression.Named(fmt.Sprintf("segment-%06d.jsonl", a.Index.Next))
+	segmentPath := filepath.Join(a.Path, segmentFile)
+	partialPath := segmentPath + ".partial"
+
+	partial, err := os.OpenFile(partialPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToWriteSegment, err)
+	}
+
+	// the partial segment is never left behind, once it was renamed there is nothing left to remove
+	defer func() {
+		partial.Close()
+		os.Remove(partialPath)
+	}()
+
+	writer, err := compression.NewWriter(partial, a.Compression)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToWriteSegment, err)
+	}
+
+	if err := write(writer); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToWriteSegment, err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToWriteSegment, err)
+	}
+
+	if err := partial.Sync(); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToWriteSegment, err)
+	}
+
+	partial.Close()
+
+	segment, err := describeSegment(partialPath)
+	if err != nil {
+		return err
+	}
+
+	segment.File = segmentFile
+
+	previous := a.Index
+
+	index := Index{Next: (previous.Next + 1)}
+
+	if !replace {
+		index.Segments = slices.Clone(previous.Segments)
+	}
+
+	if !replace {
+		index.Coverage = slices.Clone(previous.Coverage)
+	}
+
+	if covered != nil {
+		index.Coverage = addCoverage(index.Coverage, *covered)
+	}
+
+	// an archive that doesn't record a coverage yet works it out from its segments on the next sync instead
+	if covered == nil && len(index.Coverage) > 0 && segment.Lines > 0 {
+		index.Coverage = addCoverage(index.Coverage, a.downloaded(segment))
+	}
+
+	// nothing was saved, the segments that are already in the archive are still all of it unless they are replaced
+	if segment.Lines > 0 {
+		if err := os.Rename(partialPath, segmentPath); err != nil {
+			return fmt.Errorf("%w: %w", ErrFailedToWriteSegment, err)
+		}
+
+		index.Segments = append(index.Segments, segment)
+	}
+
+	// the segments don't overlap, only a burst of logs at the same timestamp can be split over the end of one and the start of the next
+	slices.SortStableFunc(index.Segments, func(a, b Segment) int {
+		return cmp.Or(a.Oldest.Compare(b.Oldest), a.Newest.Compare(b.Newest))
+	})
+
+	if err := a.writeIndex(index); err != nil {
+		return err
+	}
+
+	// the replaced segments aren't part of the archive anymore
+	if replace {
+		for _, segment := range previous.Segments {
+			if err := os.Remove(filepath.Join(a.Path, segment.File)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("%w: %w", ErrFailedToRemoveSegment, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// downloaded returns the time range a segment saved by another download has every log of. Resuming downloads everything
+// up to the oldest log of the archive and catching up everything from its newest log, so the range reaches the archive
+func (a *Archive) downloaded(segment Segment) Interval {
+	interval := Interval{Start: segment.Oldest, End: segment.Newest}
+
+	if len(a.Index.Segments) == 0 {
+		return interval
+	}
+
+	if oldest, _ := a.Oldest(); !segment.Newest.After(oldest) {
+		interval.End = oldest
+	}
+
+	if newest := a.Newest(); !segment.Oldest.Before(newest) {
+		interval.Start = newest
+	}
+
+	return interval
+}
+
+// writeIndex replaces the index of the archive, it is written next to it and synced before it is renamed into place
+func (a *Archive) writeIndex(index Index) error {
+	content, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToWriteIndex, err)
+	}
+
+	indexPath := filepath.Join(a.Path, INDEX_FILE_NAME)
+	partialPath := indexPath + ".partial"
+
+	partial, err := os.OpenFile(partialPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToWriteIndex, err)
+	}
+
+	defer func() {
+		partial.Close()
+		os.Remove(partialPath)
+	}()
+
+	if _, err := partial.Write(append(content, '\n')); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToWriteIndex, err)
+	}
+
+	if err := partial.Sync(); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToWriteIndex, err)
+	}
+
+	partial.Close()
+
+	if err := os.Rename(partialPath, indexPath); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToWriteIndex, err)
+	}
+
+	if err := tools.SyncDir(a.Path); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToWriteIndex, err)
+	}
+
+	a.Index = index
+
+	return nil
+}
+
+// RemoveOrphans removes the segments that were written but never made it into the index, and the ones that were replaced
+// but not removed yet, because the save that wrote them was cut off
+func (a *Archive) RemoveOrphans() error {
+	entries, err := os.ReadDir(a.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToReadSegment, err)
+	}
+
+	for _, entry := range entries {
+		if entry.Name() == INDEX_FILE_NAME || !strings.HasPrefix(entry.Name(), "segment-") {
+			continue
+		}
+
+		indexed := slices.ContainsFunc(a.Index.Segments, func(segment Segment) bool {
+			return segment.File == entry.Name()
+		})
+
+		if indexed {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(a.Path, entry.Name())); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w: %w", ErrFailedToRemoveSegment, err)
+		}
+	}
+
+	return nil
+}
+
+// Compact concatenates the segments into a single log file, which is built next to the output and renamed into place once it is complete.
+// The log file is compressed with the codec its name ends in
+func (a *Archive) Compact(output string) error {
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToCompactArchive, err)
+	}
+
+	partialPath := output + ".partial"
+
+	partial, err := os.OpenFile(partialPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToCompactArchive, err)
+	}
+
+	defer func() {
+		partial.Close()
+		os.Remove(partialPath)
+	}()
+
+	writer, err := compression.NewWriter(partial, compression.ForFile(output))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToCompactArchive, err)
+	}
+
+	for _, segmentPath := range a.SegmentPaths() {
+		if err := copySegment(writer, segmentPath); err != nil {
+			return err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToCompactArchive, err)
+	}
+
+	if err := partial.Sync(); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToCompactArchive, err)
+	}
+
+	partial.Close()
+
+	if err := os.Rename(partialPath, output); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToCompactArchive, err)
+	}
+
+	return tools.SyncDir(filepath.Dir(output))
+}
+
+func copySegment(output io.Writer, segmentPath string) error {
+	segment, err := compression.Open(segmentPath)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToReadSegment, err)
+	}
+
+	defer segment.Close()
+
+	if _, err := io.Copy(output, segment); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToCompactArchive, err)
+	}
+
+	return nil
+}
+
+// describeSegment reads the time range and size of a segment that was just written
+func describeSegment(segmentPath string) (Segment, error) {
+	stat, err := os.Stat(segmentPath)
+	if err != nil {
+		return Segment{}, fmt.Errorf("%w: %w", ErrFailedToReadSegment, err)
+	}
+
+	segment := Segment{Size: stat.Size()}
+
+	if segment.Size == 0 {
+		return segment, nil
+	}
+
+	if segment.Lines, err = tools.CountLines(segmentPath); err != nil {
+		return Segment{}, err
+	}
+
+	if segment.Oldest, err = tools.ReadFirstLineTimestamp(segmentPath); err != nil {
+		return Segment{}, err
+	}
+
+	if segment.AtOldest, err = tools.CountFirstTimestampLines(segmentPath); err != nil {
+		return Segment{}, err
+	}
+
+	if segment.Newest, err = tools.ReadLastLineTimestamp(segmentPath); err != nil {
+		return Segment{}, err
+	}
+
+	return segment, nil
+}
@@ -0,0 +1,136 @@
+package archive
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+var testBase = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func at(second int) time.Time {
+	return testBase.Add(time.Duration(second) * time.Second)
+}
+
+// writeLogs returns a segment writer with one log at every second from oldest to newest
+func writeLogs(oldest int, newest int) func(output io.Writer) error {
+	return func(output io.Writer) error {
+		for second := oldest; second <= newest; second++ {
+			if _, err := fmt.Fprintf(output, "{\"timestamp\":%q,\"message\":\"log\"}\n", at(second).Format(time.RFC3339Nano)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// reopen reads the archive from disk again, the way the next run sees it
+func reopen(t *testing.T, archive *Archive) *Archive {
+	t.Helper()
+
+	reopened, err := Open(archive.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return reopened
+}
+
+func requireCoverage(t *testing.T, archive *Archive, want ...Interval) {
+	t.Helper()
+
+	if !slices.Equal(archive.Index.Coverage, want) {
+		t.Fatalf("expected the coverage %v, got %v", want, archive.Index.Coverage)
+	}
+}
+
+func TestCoverageSurvivesOtherDownloads(t *testing.T) {
+	archive, err := Open(filepath.Join(t.TempDir(), "logs"+EXTENSION))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a sync of a time range that doesn't reach back to the oldest log
+	if err := archive.SyncSegment(writeLogs(10, 20), Interval{Start: at(10), End: at(20)}); err != nil {
+		t.Fatal(err)
+	}
+
+	archive = reopen(t, archive)
+
+	// resuming adds the older logs
+	if err := archive.AddSegment(writeLogs(5, 9)); err != nil {
+		t.Fatal(err)
+	}
+
+	archive = reopen(t, archive)
+
+	requireCoverage(t, archive, Interval{Start: at(5), End: at(20)})
+
+	// the next sync only has the newer logs left to download
+	if gaps := archive.Gaps(at(30)); !slices.Equal(gaps, []Interval{{End: at(5)}, {Start: at(20).Add(time.Nanosecond), End: at(30)}}) {
+		t.Fatalf("expected only the time ranges before and after the downloaded logs to be missing, got %v", gaps)
+	}
+
+	if err := archive.SyncSegment(writeLogs(21, 30), Interval{Start: at(20).Add(time.Nanosecond), End: at(30)}); err != nil {
+		t.Fatal(err)
+	}
+
+	archive = reopen(t, archive)
+
+	requireCoverage(t, archive, Interval{Start: at(5), End: at(30)})
+
+	if archive.Lines() != 26 {
+		t.Fatalf("expected every log once, got %d", archive.Lines())
+	}
+}
+
+func TestCoverageKeepsTheGapsOfASync(t *testing.T) {
+	archive, err := Open(filepath.Join(t.TempDir(), "logs"+EXTENSION))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := archive.SyncSegment(writeLogs(0, 10), Interval{End: at(10)}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := archive.SyncSegment(writeLogs(20, 30), Interval{Start: at(20), End: at(30)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// catching up adds the newest logs, the gap the syncs left is still missing
+	if err := archive.AddSegment(writeLogs(31, 35)); err != nil {
+		t.Fatal(err)
+	}
+
+	archive = reopen(t, archive)
+
+	requireCoverage(t, archive, Interval{End: at(10)}, Interval{Start: at(20), End: at(35)})
+}
+
+func TestReplaceSegmentsStartsTheCoverageOver(t *testing.T) {
+	archive, err := Open(filepath.Join(t.TempDir(), "logs"+EXTENSION))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := archive.SyncSegment(writeLogs(10, 20), Interval{Start: at(10), End: at(20)}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := archive.ReplaceSegments(writeLogs(0, 5)); err != nil {
+		t.Fatal(err)
+	}
+
+	archive = reopen(t, archive)
+
+	requireCoverage(t, archive)
+
+	if archive.Lines() != 6 {
+		t.Fatalf("expected only the logs of the new segment, got %d", archive.Lines())
+	}
+}
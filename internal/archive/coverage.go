@@ -0,0 +1,103 @@
+package archive
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"main/internal/tools"
+)
+
+// Interval is a time range that every log of is in the archive, both ends included. The zero Start stands for
+// the oldest log that could still be downloaded when the interval was synced
+type Interval struct {
+	Start time.Time `json:"start,omitzero"`
+	End   time.Time `json:"end"`
+}
+
+// FromOldest reports if the interval reaches back to the oldest log that could be downloaded
+func (i Interval) FromOldest() bool {
+	return i.Start.IsZero()
+}
+
+// Complete reports if the coverage of the archive has no gaps from the oldest log that could be downloaded to its newest interval
+func (a *Archive) Complete() bool {
+	return len(a.Index.Coverage) == 1 && a.Index.Coverage[0].FromOldest()
+}
+
+// Gaps returns the time ranges up to now that the coverage of the archive leaves out, oldest first. The end of a gap is
+// the start of the interval after it, the logs at that timestamp can be missing when the download that covered it was cut off
+func (a *Archive) Gaps(now time.Time) []Interval {
+	coverage := a.Index.Coverage
+
+	if len(coverage) == 0 {
+		return []Interval{{End: now}}
+	}
+
+	gaps := []Interval{}
+
+	if !coverage[0].FromOldest() {
+		gaps = append(gaps, Interval{End: coverage[0].Start})
+	}
+
+	for i := 1; i < len(coverage); i++ {
+		gaps = append(gaps, Interval{Start: coverage[i-1].End.Add(time.Nanosecond), End: coverage[i].Start})
+	}
+
+	if last := coverage[len(coverage)-1]; last.End.Before(now) {
+		gaps = append(gaps, Interval{Start: last.End.Add(time.Nanosecond), End: now})
+	}
+
+	return gaps
+}
+
+// AssumeCovered sets the coverage of an archive that doesn't record one, it is saved with the next segment
+func (a *Archive) AssumeCovered(interval Interval) {
+	a.Index.Coverage = []Interval{interval}
+}
+
+// LogsAt returns the number of logs in the archive at the timestamp
+func (a *Archive) LogsAt(timestamp time.Time) (int, error) {
+	count := 0
+
+	for i, segment := range a.Index.Segments {
+		if timestamp.Before(segment.Oldest) || timestamp.After(segment.Newest) {
+			continue
+		}
+
+		logs, err := tools.CountTimestampLines(a.SegmentPaths()[i], timestamp)
+		if err != nil {
+			return 0, err
+		}
+
+		count += logs
+	}
+
+	return count, nil
+}
+
+// addCoverage adds the interval to the coverage and merges the intervals that overlap or touch, oldest first
+func addCoverage(coverage []Interval, interval Interval) []Interval {
+	coverage = append(slices.Clone(coverage), interval)
+
+	slices.SortFunc(coverage, func(a, b Interval) int {
+		return cmp.Or(a.Start.Compare(b.Start), a.End.Compare(b.End))
+	})
+
+	merged := []Interval{coverage[0]}
+
+	for _, next := range coverage[1:] {
+		last := &merged[len(merged)-1]
+
+		if next.Start.After(last.End.Add(time.Nanosecond)) {
+			merged = append(merged, next)
+			continue
+		}
+
+		if next.End.After(last.End) {
+			last.End = next.End
+		}
+	}
+
+	return merged
+}
@@ -0,0 +1,13 @@
+package archive
+
+import "errors"
+
+var (
+	ErrFailedToReadIndex      = errors.New("failed to read archive index")
+	ErrFailedToParseIndex     = errors.New("failed to parse archive index")
+	ErrFailedToWriteIndex     = errors.New("failed to write archive index")
+	ErrFailedToWriteSegment   = errors.New("failed to write archive segment")
+	ErrFailedToReadSegment    = errors.New("failed to read archive segment")
+	ErrFailedToRemoveSegment  = errors.New("failed to remove archive segment")
+	ErrFailedToCompactArchive = errors.New("failed to compact archive")
+)
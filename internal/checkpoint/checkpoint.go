@@ -0,0 +1,293 @@
+package checkpoint
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"main/internal/manifest"
+	"main/internal/railway"
+)
+
+// Mode is how the logs of a download end up in the log file
+type Mode string
+
+const (
+	ModeDownload Mode = "download"
+	ModeResume   Mode = "resume"
+	ModeCatchUp  Mode = "catch-up"
+)
+
+// Session is the first event of a journal, it holds everything needed to continue the download the same way it started
+type Session struct {
+	LogFileName string         `json:"logFileName"`
+	Query       manifest.Query `json:"query"`
+	Mode        Mode           `json:"mode"`
+
+	// LogFileSize is the size of the log file that is resumed or caught up on, the download can't be continued once it changed
+	LogFileSize int64 `json:"logFileSize"`
+
+	ResumeFromTimestamp     *time.Time `json:"resumeFromTimestamp,omitempty"`
+	ResumeSavedAtTimestamp  int        `json:"resumeSavedAtTimestamp,omitempty"`
+	CatchUpFromTimestamp    *time.Time `json:"catchUpFromTimestamp,omitempty"`
+	CatchUpSavedAtTimestamp int        `json:"catchUpSavedAtTimestamp,omitempty"`
+
+	// Since and Until are the time range that was downloaded, relative durations and now are fixed when the download starts
+	Since *time.Time `json:"since,omitempty"`
+	Until *time.Time `json:"until,omitempty"`
+
+	Shards int `json:"shards"`
+
+	StartedAt time.Time `json:"startedAt"`
+}
+
+// NewSession describes a download that is about to start with the options
+func NewSession(logFileName string, query manifest.Query, mode Mode, logFileSize int64, options railway.GetLogsOptions) Session {
+	return Session{
+		LogFileName:             logFileName,
+		Query:                   query,
+		Mode:                    mode,
+		LogFileSize:             logFileSize,
+		ResumeFromTimestamp:     optionalTime(options.ResumeFromTimestamp),
+		ResumeSavedAtTimestamp:  options.ResumeSavedAtTimestamp,
+		CatchUpFromTimestamp:    optionalTime(options.CatchUpFromTimestamp),
+		CatchUpSavedAtTimestamp: options.CatchUpSavedAtTimestamp,
+		Since:                   optionalTime(options.Since),
+		Until:                   optionalTime(options.Until),
+		Shards:                  options.Shards,
+		StartedAt:               time.Now().UTC(),
+	}
+}
+
+// Checkpoint is everything the journal of an interrupted download recorded
+type Checkpoint struct {
+	Session Session
+
+	Windows    []Window
+	Chunks     []Chunk
+	ShardsDone map[int]bool
+
+	// Done means every log was downloaded and only saving them to the log file was left
+	Done bool
+
+	// Saving means the logs were being saved to the log file when the download was cut off, and Saved that they were
+	Saving bool
+	Saved  bool
+}
+
+// Load reads the journal in the tmp path, nil when there is no journal. A line that was cut off by a crash is the last one, it is left out
+func Load(tmpPath string) (*Checkpoint, error) {
+	file, err := os.Open(journalPath(tmpPath))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToReadJournal, err)
+	}
+
+	defer file.Close()
+
+	checkpoint := &Checkpoint{ShardsDone: map[int]bool{}}
+	hasSession := false
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		event := entry{}
+
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			break
+		}
+
+		switch {
+		case event.Session != nil:
+			checkpoint.Session = *event.Session
+			hasSession = true
+		case event.Windows != nil:
+			checkpoint.Windows = event.Windows
+		case event.Chunk != nil:
+			checkpoint.Chunks = append(checkpoint.Chunks, *event.Chunk)
+		case event.ShardDone != nil:
+			checkpoint.ShardsDone[*event.ShardDone] = true
+		case event.Done:
+			checkpoint.Done = true
+		case event.Saving:
+			checkpoint.Saving = true
+		case event.Saved:
+			checkpoint.Saved = true
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToReadJournal, err)
+	}
+
+	// the journal was cut off before the session was written, nothing was downloaded yet
+	if !hasSession {
+		return nil, nil
+	}
+
+	return checkpoint, nil
+}
+
+// Logs returns the number of logs in the chunk files
+func (c *Checkpoint) Logs() int64 {
+	logs := int64(0)
+
+	for _, chunk := range c.Chunks {
+		logs += int64(chunk.Logs)
+	}
+
+	return logs
+}
+
+// Files returns the paths of the chunk files in the tmp path
+func (c *Checkpoint) Files(tmpPath string) []string {
+	files := make([]string, len(c.Chunks))
+
+	for i, chunk := range c.Chunks {
+		files[i] = filepath.Join(tmpPath, chunk.File)
+	}
+
+	return files
+}
+
+// LastSequence returns the sequence of the newest chunk file, new chunk files continue after it
+func (c *Checkpoint) LastSequence() int {
+	sequence := 0
+
+	for _, chunk := range c.Chunks {
+		sequence = max(sequence, chunk.Sequence)
+	}
+
+	return sequence
+}
+
+// Verify returns an error when the download can't be continued with the log file, query and mode of the new one
+func (c *Checkpoint) Verify(logFileName string, query manifest.Query, mode Mode, logFileSize int64) error {
+	switch {
+	case c.Session.LogFileName != logFileName:
+		return fmt.Errorf("%w: it was downloading %s", ErrSessionMismatch, c.Session.LogFileName)
+	case c.Session.Mode != mode:
+		return fmt.Errorf("%w: it was a %s, not a %s", ErrSessionMismatch, c.Session.Mode, mode)
+	case c.Session.LogFileSize != logFileSize:
+		return fmt.Errorf("%w: %s changed since it started", ErrSessionMismatch, logFileName)
+	}
+
+	if err := query.Verify(c.Session.Query); err != nil {
+		return fmt.Errorf("%w: %w", ErrSessionMismatch, err)
+	}
+
+	return nil
+}
+
+// Continue returns the options that download the logs the chunk files don't have yet: a download that pages backwards continues
+// from the oldest log in the chunk files and a catch up from the newest, a sharded download continues every window on its own
+func (c *Checkpoint) Continue(options railway.GetLogsOptions) railway.GetLogsOptions {
+	options.ResumeFromTimestamp = timeOf(c.Session.ResumeFromTimestamp)
+	options.ResumeSavedAtTimestamp = c.Session.ResumeSavedAtTimestamp
+	options.CatchUpFromTimestamp = timeOf(c.Session.CatchUpFromTimestamp)
+	options.CatchUpSavedAtTimestamp = c.Session.CatchUpSavedAtTimestamp
+	options.Since = timeOf(c.Session.Since)
+	options.Until = timeOf(c.Session.Until)
+	options.Shards = c.Session.Shards
+
+	if c.Session.Mode == ModeCatchUp {
+		if newest, savedAtNewest, ok := newestOf(c.Chunks); ok {
+			// the chunk files can still be at the timestamp the log file ended at
+			if newest.Equal(options.CatchUpFromTimestamp) {
+				savedAtNewest += options.CatchUpSavedAtTimestamp
+			}
+
+			options.CatchUpFromTimestamp = newest
+			options.CatchUpSavedAtTimestamp = savedAtNewest
+		}
+
+		return options
+	}
+
+	// the windows of a sharded download continue on their own
+	if c.Windows != nil {
+		options.Windows = make([]railway.TimeWindow, len(c.Windows))
+
+		for i, window := range c.Windows {
+			options.Windows[i] = railway.TimeWindow{
+				Start:                  window.Start,
+				End:                    window.End,
+				ResumeFromTimestamp:    timeOf(window.ResumeFromTimestamp),
+				ResumeSavedAtTimestamp: window.ResumeSavedAtTimestamp,
+				Done:                   c.ShardsDone[i],
+			}
+
+			if oldest, savedAtOldest, ok := oldestOf(c.Chunks, i); ok {
+				options.Windows[i].ResumeFromTimestamp = oldest
+				options.Windows[i].ResumeSavedAtTimestamp = savedAtOldest
+			}
+		}
+
+		return options
+	}
+
+	if oldest, savedAtOldest, ok := oldestOf(c.Chunks, 0); ok {
+		// the chunk files can still be at the timestamp the log file started at
+		if c.Session.Mode == ModeResume && oldest.Equal(options.ResumeFromTimestamp) {
+			savedAtOldest += options.ResumeSavedAtTimestamp
+		}
+
+		options.ResumeFromTimestamp = oldest
+		options.ResumeSavedAtTimestamp = savedAtOldest
+	}
+
+	return options
+}
+
+// oldestOf returns the oldest log in the chunks of the shard and the number of logs at that timestamp,
+// a burst can be split over more than one chunk
+func oldestOf(chunks []Chunk, shard int) (time.Time, int, bool) {
+	oldest := time.Time{}
+	savedAtOldest := 0
+
+	for _, chunk := range chunks {
+		// every log of a chunk without logs was already written by an earlier source
+		if chunk.Shard != shard || chunk.Logs == 0 {
+			continue
+		}
+
+		switch {
+		case oldest.IsZero() || chunk.Oldest.Before(oldest):
+			oldest = chunk.Oldest
+			savedAtOldest = chunk.AtOldest
+		case chunk.Oldest.Equal(oldest):
+			savedAtOldest += chunk.AtOldest
+		}
+	}
+
+	return oldest, savedAtOldest, !oldest.IsZero()
+}
+
+// newestOf returns the newest log in the chunks and the number of logs at that timestamp
+func newestOf(chunks []Chunk) (time.Time, int, bool) {
+	newest := time.Time{}
+	savedAtNewest := 0
+
+	for _, chunk := range chunks {
+		if chunk.Logs == 0 {
+			continue
+		}
+
+		switch {
+		case newest.IsZero() || chunk.Newest.After(newest):
+			newest = chunk.Newest
+			savedAtNewest = chunk.AtNewest
+		case chunk.Newest.Equal(newest):
+			savedAtNewest += chunk.AtNewest
+		}
+	}
+
+	return newest, savedAtNewest, !newest.IsZero()
+}
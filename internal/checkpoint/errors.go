@@ -0,0 +1,13 @@
+package checkpoint
+
+import "errors"
+
+var (
+	ErrFailedToCreateJournal = errors.New("failed to create checkpoint journal")
+	ErrFailedToOpenJournal   = errors.New("failed to open checkpoint journal")
+	ErrFailedToReadJournal   = errors.New("failed to read checkpoint journal")
+	ErrFailedToWriteJournal  = errors.New("failed to write checkpoint journal")
+	ErrFailedToRemoveJournal = errors.New("failed to remove checkpoint journal")
+	ErrSessionMismatch       = errors.New("the interrupted download can't be continued")
+	ErrUnfinishedWindows     = errors.New("not every window of the sharded download was downloaded, saving them would leave gaps between them")
+)
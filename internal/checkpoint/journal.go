@@ -0,0 +1,237 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"main/internal/railway"
+)
+
+// JOURNAL_FILE_NAME is the journal of a download, it is kept in the tmp path next to the chunk files
+var JOURNAL_FILE_NAME = "checkpoint.journal"
+
+// Journal records the progress of a download as it happens, one json line per event, so a download that was killed or crashed
+// can be continued from the chunk files it already wrote. Every event is synced to disk before the next one is written
+type Journal struct {
+	mu   sync.Mutex
+	file *os.File
+
+	// the first event that couldn't be recorded, the journal stops recording after it
+	err error
+
+	// the download was split into windows and every one of them has all of its logs
+	sharded bool
+	done    bool
+}
+
+// entry is a single line of the journal, exactly one of its fields is set
+type entry struct {
+	Session   *Session `json:"session,omitempty"`
+	Windows   []Window `json:"windows,omitempty"`
+	Chunk     *Chunk   `json:"chunk,omitempty"`
+	ShardDone *int     `json:"shardDone,omitempty"`
+	Done      bool     `json:"done,omitempty"`
+	Saving    bool     `json:"saving,omitempty"`
+	Saved     bool     `json:"saved,omitempty"`
+}
+
+// Chunk is a chunk file that was written to the tmp path
+type Chunk struct {
+	// File is the name of the chunk file in the tmp path
+	File string `json:"file"`
+
+	Sequence int `json:"sequence"`
+	Shard    int `json:"shard"`
+
+	// Logs is the number of logs that were written to the chunk file
+	Logs int `json:"logs"`
+
+	// Oldest and Newest are the timestamps of the oldest and newest log that was written to the chunk file, and AtOldest and AtNewest
+	// the number of written logs at them, a download continues from one of them
+	Oldest   time.Time `json:"oldest"`
+	Newest   time.Time `json:"newest"`
+	AtOldest int       `json:"atOldest"`
+	AtNewest int       `json:"atNewest"`
+}
+
+// Window is a window of a sharded download
+type Window struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+
+	ResumeFromTimestamp    *time.Time `json:"resumeFromTimestamp,omitempty"`
+	ResumeSavedAtTimestamp int        `json:"resumeSavedAtTimestamp,omitempty"`
+}
+
+func journalPath(tmpPath string) string {
+	return filepath.Join(tmpPath, JOURNAL_FILE_NAME)
+}
+
+// Create starts the journal of a new download in the tmp path, replacing the journal that was there
+func Create(tmpPath string, session Session) (*Journal, error) {
+	if err := os.MkdirAll(tmpPath, 0755); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToCreateJournal, err)
+	}
+
+	file, err := os.OpenFile(journalPath(tmpPath), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToCreateJournal, err)
+	}
+
+	journal := &Journal{file: file}
+
+	if err := journal.record(entry{Session: &session}); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return journal, nil
+}
+
+// Open continues the journal of an interrupted download in the tmp path
+func Open(tmpPath string) (*Journal, error) {
+	file, err := os.OpenFile(journalPath(tmpPath), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToOpenJournal, err)
+	}
+
+	return &Journal{file: file}, nil
+}
+
+// RecordWindows records the windows a sharded download is split into
+func (j *Journal) RecordWindows(windows []railway.TimeWindow) error {
+	recorded := make([]Window, len(windows))
+
+	for i, window := range windows {
+		recorded[i] = Window{
+			Start:                  window.Start,
+			End:                    window.End,
+			ResumeFromTimestamp:    optionalTime(window.ResumeFromTimestamp),
+			ResumeSavedAtTimestamp: window.ResumeSavedAtTimestamp,
+		}
+	}
+
+	j.mu.Lock()
+	j.sharded = true
+	j.mu.Unlock()
+
+	return j.record(entry{Windows: recorded})
+}
+
+// RecordChunk records a chunk file once it is completely written
+func (j *Journal) RecordChunk(chunk Chunk) error {
+	return j.record(entry{Chunk: &chunk})
+}
+
+// RecordShardDone records that a window of a sharded download has every log
+func (j *Journal) RecordShardDone(shard int) error {
+	return j.record(entry{ShardDone: &shard})
+}
+
+// RecordDone records that every log was downloaded, only saving them to the log file is left
+func (j *Journal) RecordDone() error {
+	j.mu.Lock()
+	j.done = true
+	j.mu.Unlock()
+
+	return j.record(entry{Done: true})
+}
+
+// UnfinishedWindows reports if the download was split into windows and stopped before every one of them had all of its logs.
+// The windows that finished don't touch each other and resuming a log file only downloads the logs older than it,
+// so only continuing the download from its journal can fill the gaps between them
+func (j *Journal) UnfinishedWindows() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.sharded && !j.done
+}
+
+// RecordSaving records that the logs are about to be saved to the log file, a catch up that is cut off after it has to be undone
+func (j *Journal) RecordSaving() error {
+	return j.record(entry{Saving: true})
+}
+
+// RecordSaved records that the logs were saved to the log file, only removing the work directory is left
+func (j *Journal) RecordSaved() error {
+	return j.record(entry{Saved: true})
+}
+
+// Err returns the first event that couldn't be recorded
+func (j *Journal) Err() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.err
+}
+
+func (j *Journal) Close() error {
+	return j.file.Close()
+}
+
+func (j *Journal) record(event entry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.err != nil {
+		return j.err
+	}
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		j.err = fmt.Errorf("%w: %w", ErrFailedToWriteJournal, err)
+		return j.err
+	}
+
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		j.err = fmt.Errorf("%w: %w", ErrFailedToWriteJournal, err)
+		return j.err
+	}
+
+	// the event only counts once it is on disk
+	if err := j.file.Sync(); err != nil {
+		j.err = fmt.Errorf("%w: %w", ErrFailedToWriteJournal, err)
+		return j.err
+	}
+
+	return nil
+}
+
+// Exists reports if there is a journal in the tmp path
+func Exists(tmpPath string) bool {
+	_, err := os.Stat(journalPath(tmpPath))
+
+	return err == nil
+}
+
+// Remove deletes the journal from the tmp path, once its download was saved or thrown away
+func Remove(tmpPath string) error {
+	if err := os.Remove(journalPath(tmpPath)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %w", ErrFailedToRemoveJournal, err)
+	}
+
+	return nil
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	t = t.UTC()
+
+	return &t
+}
+
+func timeOf(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+
+	return *t
+}
@@ -0,0 +1,71 @@
+package checkpoint
+
+import (
+	"testing"
+	"time"
+
+	"main/internal/railway"
+)
+
+func TestUnfinishedWindows(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	windows := []railway.TimeWindow{{Start: start, End: start.Add(time.Hour)}, {Start: start.Add(time.Hour), End: start.Add(2 * time.Hour)}}
+
+	tmpPath := t.TempDir()
+
+	journal, err := Create(tmpPath, Session{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if journal.UnfinishedWindows() {
+		t.Fatal("expected a download that isn't split into windows to be saved when it stops")
+	}
+
+	if err := journal.RecordWindows(windows); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := journal.RecordShardDone(0); err != nil {
+		t.Fatal(err)
+	}
+
+	if !journal.UnfinishedWindows() {
+		t.Fatal("expected a sharded download with a window left to not be saved")
+	}
+
+	journal.Close()
+
+	// continuing the download records its windows again before downloading the rest of them
+	journal, err = Open(tmpPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer journal.Close()
+
+	if err := journal.RecordWindows(windows); err != nil {
+		t.Fatal(err)
+	}
+
+	if !journal.UnfinishedWindows() {
+		t.Fatal("expected a continued sharded download with a window left to not be saved")
+	}
+
+	if err := journal.RecordDone(); err != nil {
+		t.Fatal(err)
+	}
+
+	if journal.UnfinishedWindows() {
+		t.Fatal("expected a sharded download with every window downloaded to be saved")
+	}
+
+	interrupted, err := Load(tmpPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !interrupted.Done || len(interrupted.Windows) != len(windows) {
+		t.Fatalf("expected the journal to hold the windows and that every log was downloaded, got %+v", interrupted)
+	}
+}
@@ -0,0 +1,191 @@
+package compression
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Codec is how a log file is compressed, the empty codec leaves it as plain json lines
+type Codec string
+
+const (
+	CodecNone Codec = ""
+	CodecGzip Codec = "gzip"
+	CodecZstd Codec = "zstd"
+)
+
+// CODECS are the codecs a log file can be compressed with
+var CODECS = []Codec{CodecGzip, CodecZstd}
+
+// the first bytes of a gzip member and a zstd frame
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// Parse returns the codec with the name, an empty name leaves the log file uncompressed
+func Parse(name string) (Codec, error) {
+	codec := Codec(strings.ToLower(name))
+
+	if codec != CodecNone && !slices.Contains(CODECS, codec) {
+		return CodecNone, fmt.Errorf("%w: %s", ErrUnknownCodec, name)
+	}
+
+	return codec, nil
+}
+
+// Extension returns what is added to the name of a file that is compressed with the codec
+func (c Codec) Extension() string {
+	switch c {
+	case CodecGzip:
+		return ".gz"
+	case CodecZstd:
+		return ".zst"
+	default:
+		return ""
+	}
+}
+
+// Named returns the file name with the extension of the codec, a name that already has it is left as it is
+func (c Codec) Named(filename string) string {
+	if strings.HasSuffix(filename, c.Extension()) {
+		return filename
+	}
+
+	return filename + c.Extension()
+}
+
+// ForFile returns the codec a file is written with, which is decided by the extension of its name
+func ForFile(filename string) Codec {
+	for _, codec := range CODECS {
+		if strings.HasSuffix(filename, codec.Extension()) {
+			return codec
+		}
+	}
+
+	return CodecNone
+}
+
+// NewWriter compresses what is written to it into w with the codec. It has to be closed to finish the compressed stream,
+// which leaves w open
+func NewWriter(w io.Writer, codec Codec) (io.WriteCloser, error) {
+	switch codec {
+	case CodecGzip:
+		return gzip.NewWriter(w), nil
+	case CodecZstd:
+		encoder, err := zstd.NewWriter(w)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrFailedToCreateCompressor, err)
+		}
+
+		return encoder, nil
+	default:
+		return nopWriteCloser{w}, nil
+	}
+}
+
+// NewReader decompresses r, the compression is detected from its first bytes so plain and compressed files are read alike.
+// Concatenated gzip members and zstd frames are read as a single stream, which is how appended logs end up in a compressed file
+func NewReader(r io.Reader) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(r)
+
+	codec, err := detect(buffered)
+	if err != nil {
+		return nil, err
+	}
+
+	switch codec {
+	case CodecGzip:
+		reader, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrFailedToCreateDecompressor, err)
+		}
+
+		return reader, nil
+	case CodecZstd:
+		decoder, err := zstd.NewReader(buffered, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrFailedToCreateDecompressor, err)
+		}
+
+		return decoder.IOReadCloser(), nil
+	default:
+		return io.NopCloser(buffered), nil
+	}
+}
+
+// Open opens the file for reading through NewReader, closing the reader closes the file too.
+// The error of opening the file is returned as it is
+func Open(filename string) (io.ReadCloser, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := NewReader(file)
+	if err != nil {
+		file.Close()
+
+		return nil, err
+	}
+
+	return readCloser{Reader: reader, close: func() error {
+		return errors.Join(reader.Close(), file.Close())
+	}}, nil
+}
+
+// Detect returns the codec the file is compressed with, from its first bytes
+func Detect(filename string) (Codec, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return CodecNone, err
+	}
+
+	defer file.Close()
+
+	return detect(bufio.NewReader(file))
+}
+
+// detect peeks at the first bytes of the reader without consuming them, a file too short to hold a header isn't compressed
+func detect(reader *bufio.Reader) (Codec, error) {
+	header, err := reader.Peek(len(zstdMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return CodecNone, fmt.Errorf("%w: %w", ErrFailedToDetectCompression, err)
+	}
+
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		return CodecGzip, nil
+	case bytes.HasPrefix(header, zstdMagic):
+		return CodecZstd, nil
+	default:
+		return CodecNone, nil
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+type readCloser struct {
+	io.Reader
+
+	close func() error
+}
+
+func (r readCloser) Close() error {
+	return r.close()
+}
@@ -0,0 +1,10 @@
+package compression
+
+import "errors"
+
+var (
+	ErrUnknownCodec               = errors.New("unknown compression, use gzip or zstd")
+	ErrFailedToCreateCompressor   = errors.New("failed to create compressor")
+	ErrFailedToCreateDecompressor = errors.New("failed to create decompressor")
+	ErrFailedToDetectCompression  = errors.New("failed to detect compression")
+)
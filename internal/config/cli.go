@@ -0,0 +1,149 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// cliConfig is the part of the Railway CLI's config file that is useful here
+type cliConfig struct {
+	Projects map[string]cliLinkedProject `json:"projects"`
+	User     struct {
+		Token string `json:"token"`
+	} `json:"user"`
+}
+
+// cliLinkedProject is a directory that was linked to a project with `railway link`
+type cliLinkedProject struct {
+	ProjectPath string `json:"projectPath"`
+	Project     string `json:"project"`
+	Environment string `json:"environment"`
+	Service     string `json:"service"`
+}
+
+// cliConfigPath returns the path of the Railway CLI's config file, the CLI keeps a separate file per RAILWAY_ENV
+func cliConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	filename := "config.json"
+
+	if railwayEnv := os.Getenv("RAILWAY_ENV"); railwayEnv != "" && railwayEnv != "production" {
+		filename = fmt.Sprintf("config-%s.json", railwayEnv)
+	}
+
+	return filepath.Join(home, ".railway", filename), nil
+}
+
+// applyCLIConfig falls back to the token the Railway CLI is logged in with when no token was provided,
+// and to the project that the working directory is linked to when no deployment, service, plugin, project or environment was provided
+func (c *config) applyCLIConfig() []error {
+	c.TokenSource = c.tokenSource()
+
+	if !c.UseCLIConfig.Bool() {
+		return nil
+	}
+
+	path, err := cliConfigPath()
+	if err != nil {
+		return nil
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return []error{fmt.Errorf("UseCLIConfig: failed to read the Railway CLI config at %s: %w", path, err)}
+	}
+
+	cli := cliConfig{}
+
+	if err := json.Unmarshal(content, &cli); err != nil {
+		return []error{fmt.Errorf("UseCLIConfig: failed to parse the Railway CLI config at %s: %w", path, err)}
+	}
+
+	// a custom endpoint doesn't need a token, and the CLI's token is only meant for the railway api
+	if c.TokenSource == "" && c.Endpoint == "" && cli.User.Token != "" {
+		c.AccountToken = ConfigString(cli.User.Token)
+		c.TokenSource = TokenSourceCLIConfig
+		c.CLIConfigPath = path
+	}
+
+	// a project token decides the environment itself
+	if c.ProjectToken != "" || c.DeploymentID != "" || c.PluginID != "" || c.ProjectID != "" || c.EnvironmentID != "" {
+		return nil
+	}
+
+	workingDirectory, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+
+	linkedProject, ok := cli.linkedProject(workingDirectory)
+	if !ok || linkedProject.Environment == "" {
+		return nil
+	}
+
+	// a provided service is downloaded from the linked environment, otherwise the linked service is
+	if c.ServiceID == "" {
+		c.ServiceID = ConfigString(linkedProject.Service)
+	}
+
+	c.EnvironmentID = ConfigString(linkedProject.Environment)
+	c.LinkedPath = linkedProject.ProjectPath
+	c.CLIConfigPath = path
+
+	return nil
+}
+
+// linkedProject returns the project linked to the directory or the closest of its parents, the same way the CLI finds it
+func (c *cliConfig) linkedProject(directory string) (cliLinkedProject, bool) {
+	for {
+		if linkedProject, ok := c.Projects[directory]; ok {
+			if linkedProject.ProjectPath == "" {
+				linkedProject.ProjectPath = directory
+			}
+
+			return linkedProject, true
+		}
+
+		parent := filepath.Dir(directory)
+		if parent == directory {
+			return cliLinkedProject{}, false
+		}
+
+		directory = parent
+	}
+}
+
+// tokenSource returns the environment variable the token was set in
+func (c *config) tokenSource() string {
+	for _, token := range []struct {
+		value ConfigString
+		envs  string
+	}{
+		{c.ProjectToken, "RAILWAY_TOKEN"},
+		{c.TeamToken, "RAILWAY_TEAM_TOKEN"},
+		{c.AccountToken, "RAILWAY_ACCOUNT_TOKEN,RAILWAY_API_TOKEN"},
+	} {
+		if token.value == "" {
+			continue
+		}
+
+		for _, env := range strings.Split(token.envs, ",") {
+			if os.Getenv(env) == token.value.String() {
+				return env
+			}
+		}
+	}
+
+	return ""
+}
@@ -5,41 +5,162 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"regexp"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
+	"main/internal/compression"
 	"main/internal/config/parser"
 )
 
 type ConfigString string
 
 type config struct {
-	DeploymentID  ConfigString `flag:"deployment" env:"RAILWAY_DEPLOYMENT_ID" usage:"deployment id to download logs for" validate:"uuid" required_one_of:"service_or_deployment"`
-	ServiceID     ConfigString `flag:"service" env:"RAILWAY_SERVICE_ID" usage:"service id to download logs for (required if environment is provided)" validate:"uuid" required_one_of:"service_or_deployment" required_all:"environment_and_service"`
-	EnvironmentID ConfigString `flag:"environment" env:"RAILWAY_ENVIRONMENT_ID" usage:"environment id to download logs for (required if service is provided)" validate:"uuid" required_all:"environment_and_service"`
+	DeploymentID  ConfigString `flag:"deployment" env:"RAILWAY_DEPLOYMENT_ID" usage:"deployment id to download logs for" validate:"uuid" one_of:"target"`
+	ServiceID     ConfigString `flag:"service" env:"RAILWAY_SERVICE_ID" usage:"service name or id to download logs for (requires environment)" one_of:"target"`
+	PluginID      ConfigString `flag:"plugin" env:"RAILWAY_PLUGIN_ID" usage:"plugin id to download logs for (requires environment)" validate:"uuid" one_of:"target"`
+	ProjectID     ConfigString `flag:"project" env:"RAILWAY_PROJECT_ID" usage:"project name or id to download the logs of every service in every environment for, or to look up the environment and service names in"`
+	EnvironmentID ConfigString `flag:"environment" env:"RAILWAY_ENVIRONMENT_ID" usage:"environment name or id to download logs for, on its own downloads the logs of every service in the environment"`
+
+	HttpLogs  ConfigString `flag:"http" env:"RAILWAY_HTTP_LOGS" usage:"download http logs instead of deployment logs (requires deployment)" validate:"boolean"`
+	BuildLogs ConfigString `flag:"build" env:"RAILWAY_BUILD_LOGS" usage:"download build logs instead of deployment logs (requires deployment)" validate:"boolean"`
 
 	Filter        ConfigString `flag:"filter" env:"RAILWAY_LOG_FILTER" usage:"filter to apply to logs"`
 	OverwriteFile ConfigString `flag:"overwrite" env:"RAILWAY_OVERWRITE_FILE" usage:"overwrite existing logs file" validate:"boolean"`
 	Resume        ConfigString `flag:"resume" env:"RAILWAY_RESUME" usage:"resume downloading logs from the last downloaded log" validate:"boolean"`
+	CatchUp       ConfigString `flag:"catch-up" env:"RAILWAY_CATCH_UP" usage:"append the logs that are newer than the newest log in the existing logs file" validate:"boolean"`
+	Dedupe        ConfigString `flag:"dedupe" env:"RAILWAY_DEDUPE" usage:"drop the logs that were already downloaded, matched by a hash of their timestamp, message, tags and attributes" validate:"boolean" default:"true"`
+	WithID        ConfigString `flag:"with-id" env:"RAILWAY_WITH_ID" usage:"add the hash the logs are de-duplicated by to every log as an _id field" validate:"boolean"`
+	Continue      ConfigString `flag:"continue" env:"RAILWAY_CONTINUE" usage:"continue an interrupted download from its checkpoint, or throw it away when false (asked when not set)" validate:"boolean"`
+	WorkDir       ConfigString `flag:"work-dir" env:"RAILWAY_WORK_DIR" usage:"directory the logs are kept in until they are saved, every log file gets its own locked directory in it" default:"./tmp"`
+	Output        ConfigString `flag:"output" env:"RAILWAY_OUTPUT" usage:"log file to save the logs to, a template with {project}, {environment}, {service}, {deployment}, {plugin}, {kind}, {date} and {filter_hash} placeholders, or - for stdout"`
+	Segmented     ConfigString `flag:"segmented" env:"RAILWAY_SEGMENTED" usage:"save the logs to a <name>.archive directory of segment files that resuming and catching up only add to, instead of a single log file" validate:"boolean"`
+
+	Compress       ConfigString `flag:"compress" env:"RAILWAY_COMPRESS" usage:"compress the log file, or the segments of an archive, with gzip or zstd, the extension is added to its name"`
+	CompressChunks ConfigString `flag:"compress-chunks" env:"RAILWAY_COMPRESS_CHUNKS" usage:"compress the chunk files in the work directory too (requires compress)" validate:"boolean"`
+
+	Since ConfigString `flag:"since" env:"RAILWAY_SINCE" usage:"only download logs newer than this RFC3339 timestamp or relative duration (e.g. 6h or 3d)" validate:"timestamp"`
+	Until ConfigString `flag:"until" env:"RAILWAY_UNTIL" usage:"only download logs older than this RFC3339 timestamp or relative duration (e.g. 6h or 3d)" validate:"timestamp"`
+
+	Follow ConfigString `flag:"follow" env:"RAILWAY_FOLLOW" usage:"stream new logs as they arrive instead of downloading past logs" validate:"boolean"`
+	Stdout ConfigString `flag:"stdout" env:"RAILWAY_STDOUT" usage:"write streamed logs to stdout instead of a file (requires follow)" validate:"boolean"`
+
+	Concurrency ConfigString `flag:"concurrency" env:"RAILWAY_CONCURRENCY" usage:"number of services to download logs for at the same time" validate:"positive_integer" default:"4"`
+	Shards      ConfigString `flag:"shards" env:"RAILWAY_SHARDS" usage:"number of time windows to split the logs into and download at the same time" validate:"positive_integer" default:"1"`
+
+	RetryMaxAttempts ConfigString `flag:"retry-max-attempts" env:"RAILWAY_RETRY_MAX_ATTEMPTS" usage:"number of times a failed request is sent before giving up" validate:"positive_integer" default:"5"`
+	RetryBaseDelay   ConfigString `flag:"retry-base-delay" env:"RAILWAY_RETRY_BASE_DELAY" usage:"wait before the first retry of a failed request, doubled after every failed retry" validate:"duration" default:"1s"`
+	RetryMaxDelay    ConfigString `flag:"retry-max-delay" env:"RAILWAY_RETRY_MAX_DELAY" usage:"longest wait between the retries of a failed request" validate:"duration" default:"30s"`
+
+	Endpoint     ConfigString `flag:"endpoint" env:"RAILWAY_API_ENDPOINT" usage:"graphql endpoint to download logs from instead of the railway api, e.g. a mock server" validate:"url"`
+	AccountToken ConfigString `env:"RAILWAY_ACCOUNT_TOKEN,RAILWAY_API_TOKEN" usage:"railway account or team token, a project token is detected automatically (not needed with a custom endpoint)" validate:"uuid" one_of:"token"`
+	TeamToken    ConfigString `env:"RAILWAY_TEAM_TOKEN" usage:"railway team token" validate:"uuid" one_of:"token"`
+	ProjectToken ConfigString `env:"RAILWAY_TOKEN" usage:"railway project token, the project and environment are taken from the token" validate:"uuid" one_of:"token"`
+	UseCLIConfig ConfigString `flag:"cli-config" env:"RAILWAY_USE_CLI_CONFIG" usage:"fall back to the token and linked project of the Railway CLI when none are provided" validate:"boolean" default:"true"`
+
+	// where the token and the linked environment came from, filled in after parsing so they can be reported
+	TokenSource   string
+	LinkedPath    string
+	CLIConfigPath string
+}
+
+// mockServerConfig is the config of the mock-server command
+type mockServerConfig struct {
+	Logs     ConfigString `flag:"logs" env:"RAILWAY_MOCK_LOGS" usage:"jsonl file of deployment or environment logs to serve, in the format the log downloader writes them in"`
+	HttpLogs ConfigString `flag:"http-logs" env:"RAILWAY_MOCK_HTTP_LOGS" usage:"jsonl file of http logs to serve, in the format the log downloader writes them in"`
+	Address  ConfigString `flag:"address" env:"RAILWAY_MOCK_ADDRESS" usage:"address to listen on" default:"localhost:4000"`
+}
+
+// compactConfig is the config of the compact command
+type compactConfig struct {
+	Archive  ConfigString `flag:"archive" env:"RAILWAY_ARCHIVE" usage:"segmented archive to concatenate into a single log file" required:"true"`
+	Output   ConfigString `flag:"output" env:"RAILWAY_COMPACT_OUTPUT" usage:"log file to write the logs to, the name of the archive with .jsonl when not set"`
+	Compress ConfigString `flag:"compress" env:"RAILWAY_COMPRESS" usage:"compress the log file with gzip or zstd, the extension is added to its name"`
+	WorkDir  ConfigString `flag:"work-dir" env:"RAILWAY_WORK_DIR" usage:"directory the work directories of downloads are kept in, the archive is locked in it while it is compacted" default:"./tmp"`
+}
+
+// OutputStdout is the --output that writes the logs to stdout instead of a log file
+const OutputStdout = "-"
+
+// OUTPUT_PLACEHOLDERS are the placeholders the --output template can use
+var OUTPUT_PLACEHOLDERS = []string{"project", "environment", "service", "deployment", "plugin", "kind", "date", "filter_hash"}
 
-	AccountToken ConfigString `env:"RAILWAY_ACCOUNT_TOKEN" usage:"railway account token" validate:"uuid" required:"true"`
+// outputPlaceholderRe matches a placeholder in the --output template
+var outputPlaceholderRe = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// TokenSourceCLIConfig is the token source when the token of the Railway CLI is used, otherwise it is the environment variable
+const TokenSourceCLIConfig = "cli-config"
+
+const (
+	// CommandDownload is run when no command is given
+	CommandDownload   = "download"
+	CommandMockServer = "mock-server"
+	CommandCompact    = "compact"
+	CommandSync       = "sync"
+)
+
+var (
+	Railway    = &config{}
+	MockServer = &mockServerConfig{}
+	Compact    = &compactConfig{}
+
+	// Command is the command given as the first argument
+	Command = CommandDownload
+)
+
+// startedAt is the point in time that relative durations are relative to, so every option agrees on what now is
+var startedAt = time.Now()
+
+// ReplaceOutputPlaceholders replaces every placeholder in the --output template with what replace returns for its name
+func ReplaceOutputPlaceholders(template string, replace func(name string) string) string {
+	return outputPlaceholderRe.ReplaceAllStringFunc(template, func(placeholder string) string {
+		return replace(outputPlaceholderRe.FindStringSubmatch(placeholder)[1])
+	})
 }
 
-var Railway = &config{}
+// StartedAt returns the point in time the program started, which relative durations are relative to
+func StartedAt() time.Time {
+	return startedAt
+}
 
 func init() {
+	// the command is removed from the arguments so only flags are left to parse
+	if len(os.Args) > 1 && slices.Contains([]string{CommandMockServer, CommandCompact, CommandSync}, os.Args[1]) {
+		Command = os.Args[1]
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
+
+	switch Command {
+	case CommandMockServer:
+		parse(MockServer, MockServer.validate)
+	case CommandCompact:
+		parse(Compact, Compact.validate)
+	default:
+		parse(Railway, func() []error {
+			return append(Railway.applyCLIConfig(), Railway.validate()...)
+		})
+	}
+}
+
+// parse fills the config from the flags and environment, printing the usage or the errors and exiting when needed
+func parse(cfg any, validate func() []error) {
 	// add help flag purely for the usage message
 	flag.Bool("help", false, "Show help message")
 
 	// Only parse and print usage if -help is present in arguments
 	if checkForFlag("help") {
-		parser.ParseFlags(Railway)
+		parser.ParseFlags(cfg)
 
 		flag.Usage()
 
 		os.Exit(0)
 	}
 
-	errs := parser.ParseConfig(Railway)
+	errs := parser.ParseConfig(cfg)
+
+	errs = append(errs, validate()...)
 
 	if len(errs) > 0 {
 		fmt.Println("Error parsing config")
@@ -48,17 +169,205 @@ func init() {
 	}
 }
 
+// validate checks the combinations of options that can't be expressed through struct tags
+func (c *config) validate() []error {
+	var errs []error
+
+	if c.AccountToken == "" && c.TeamToken == "" && c.ProjectToken == "" && c.Endpoint == "" {
+		errs = append(errs, errors.New("One of AccountToken, TeamToken or ProjectToken is required, set: RAILWAY_ACCOUNT_TOKEN, RAILWAY_API_TOKEN, RAILWAY_TEAM_TOKEN or RAILWAY_TOKEN in the environment"))
+	}
+
+	// a project token belongs to a single environment, which is used when no environment is provided
+	projectScoped := c.ProjectToken != ""
+
+	if !projectScoped && c.DeploymentID == "" && c.ServiceID == "" && c.PluginID == "" && c.ProjectID == "" && c.EnvironmentID == "" {
+		errs = append(errs, errors.New("One of DeploymentID, ServiceID, PluginID, ProjectID or EnvironmentID is required, provide one of: --deployment flag, --service flag, --plugin flag, --project flag or --environment flag"))
+	}
+
+	if !projectScoped && c.ServiceID != "" && c.EnvironmentID == "" {
+		errs = append(errs, errors.New("ServiceID: an environment is required to download service logs, use the --environment flag or RAILWAY_ENVIRONMENT_ID environment variable"))
+	}
+
+	if !projectScoped && c.PluginID != "" && c.EnvironmentID == "" {
+		errs = append(errs, errors.New("PluginID: an environment is required to download plugin logs, use the --environment flag or RAILWAY_ENVIRONMENT_ID environment variable"))
+	}
+
+	if c.DeploymentID != "" && c.EnvironmentID != "" {
+		errs = append(errs, errors.New("EnvironmentID: an environment can only be provided with a service or plugin, the environment of a deployment is looked up automatically"))
+	}
+
+	if c.ProjectID != "" && c.DeploymentID != "" {
+		errs = append(errs, errors.New("ProjectID: a project can't be provided with a deployment, the project of a deployment is looked up automatically"))
+	}
+
+	if c.HttpLogs.Bool() && c.DeploymentID == "" {
+		errs = append(errs, errors.New("HttpLogs: http logs can only be downloaded for a deployment, use the --deployment flag"))
+	}
+
+	if c.BuildLogs.Bool() && c.DeploymentID == "" {
+		errs = append(errs, errors.New("BuildLogs: build logs can only be downloaded for a deployment, use the --deployment flag"))
+	}
+
+	if c.HttpLogs.Bool() && c.BuildLogs.Bool() {
+		errs = append(errs, errors.New("Only one of HttpLogs or BuildLogs can be provided, but both were set"))
+	}
+
+	if c.Follow.Bool() && (c.HttpLogs.Bool() || c.BuildLogs.Bool() || c.PluginID != "" || c.ProjectWide()) {
+		errs = append(errs, errors.New("Follow: only the logs of a deployment, service or environment can be streamed"))
+	}
+
+	if c.Follow.Bool() && c.Resume.Bool() {
+		errs = append(errs, errors.New("Follow: streamed logs are always appended to the log file, the --resume flag can't be used"))
+	}
+
+	if c.Follow.Bool() && (c.Since != "" || c.Until != "") {
+		errs = append(errs, errors.New("Follow: streamed logs are always the newest logs, the --since and --until flags can't be used"))
+	}
+
+	if !c.Since.Time().IsZero() && !c.Until.Time().IsZero() && !c.Since.Time().Before(c.Until.Time()) {
+		errs = append(errs, errors.New("Since: the start of the time range must be before the end of the time range set by --until"))
+	}
+
+	if c.CatchUp.Bool() && (c.Resume.Bool() || c.OverwriteFile.Bool()) {
+		errs = append(errs, errors.New("CatchUp: only one of the --catch-up, --resume or --overwrite flags can be used"))
+	}
+
+	if c.CatchUp.Bool() && (c.BuildLogs.Bool() || c.PluginID != "") {
+		errs = append(errs, errors.New("CatchUp: only deployment, service, environment, project and http logs can be caught up on"))
+	}
+
+	if c.CatchUp.Bool() && c.Follow.Bool() {
+		errs = append(errs, errors.New("CatchUp: streamed logs are always the newest logs, the --catch-up flag can't be used with --follow"))
+	}
+
+	if c.CatchUp.Bool() && c.Since != "" {
+		errs = append(errs, errors.New("CatchUp: logs are caught up on from the newest log in the existing logs file, the --since flag can't be used"))
+	}
+
+	if c.Shards.Int() > 1 && (c.BuildLogs.Bool() || c.PluginID != "" || c.Follow.Bool() || c.CatchUp.Bool()) {
+		errs = append(errs, errors.New("Shards: only deployment, service, environment, project and http logs can be downloaded in time windows, and not while following or catching up"))
+	}
+
+	if c.RetryBaseDelay.Duration() > c.RetryMaxDelay.Duration() {
+		errs = append(errs, errors.New("RetryBaseDelay: the base delay can't be longer than the max delay set by --retry-max-delay"))
+	}
+
+	if c.Segmented.Bool() && c.Follow.Bool() {
+		errs = append(errs, errors.New("Segmented: streamed logs are appended to a single log file as they arrive, the --segmented flag can't be used with --follow"))
+	}
+
+	if Command == CommandSync && (c.Follow.Bool() || c.Resume.Bool() || c.CatchUp.Bool() || c.OverwriteFile.Bool()) {
+		errs = append(errs, errors.New("Sync: the gaps in the archive are downloaded in both directions, the --follow, --resume, --catch-up and --overwrite flags can't be used"))
+	}
+
+	if Command == CommandSync && (c.Since != "" || c.Until != "" || c.Shards.Int() > 1) {
+		errs = append(errs, errors.New("Sync: the archive covers every log that can be downloaded one gap at a time, the --since, --until and --shards flags can't be used"))
+	}
+
+	for _, placeholder := range outputPlaceholderRe.FindAllStringSubmatch(c.Output.String(), -1) {
+		if !slices.Contains(OUTPUT_PLACEHOLDERS, placeholder[1]) {
+			errs = append(errs, fmt.Errorf("Output: unknown placeholder %s, use one of {%s}", placeholder[0], strings.Join(OUTPUT_PLACEHOLDERS, "}, {")))
+		}
+	}
+
+	if c.Output == OutputStdout && (c.ProjectWide() || c.Resume.Bool() || c.CatchUp.Bool() || c.OverwriteFile.Bool() || c.Segmented.Bool() || Command == CommandSync) {
+		errs = append(errs, errors.New("Output: only the logs of a single target that are downloaded from scratch can be written to stdout, --output - can't be used with --project, --resume, --catch-up, --overwrite, --segmented or sync"))
+	}
+
+	if c.Output != "" && c.Stdout.Bool() {
+		errs = append(errs, errors.New("Output: streamed logs are written to stdout with --stdout, use --output - instead of both"))
+	}
+
+	if c.Stdout.Bool() && !c.Follow.Bool() {
+		errs = append(errs, errors.New("Stdout: only streamed logs can be written to stdout, use the --follow flag"))
+	}
+
+	if _, err := compression.Parse(c.Compress.String()); err != nil {
+		errs = append(errs, fmt.Errorf("Compress: %w", err))
+	}
+
+	if c.Compress != "" && c.Follow.Bool() {
+		errs = append(errs, errors.New("Compress: streamed logs are appended to the log file as they arrive, the --compress flag can't be used with --follow"))
+	}
+
+	if c.CompressChunks.Bool() && c.Compress == "" {
+		errs = append(errs, errors.New("CompressChunks: the chunk files are compressed with the codec of the log file, use the --compress flag"))
+	}
+
+	return errs
+}
+
+// validate checks the codec the log file is compressed with
+func (c *compactConfig) validate() []error {
+	var errs []error
+
+	if _, err := compression.Parse(c.Compress.String()); err != nil {
+		errs = append(errs, fmt.Errorf("Compress: %w", err))
+	}
+
+	return errs
+}
+
+// validate checks that there is something for the mock server to serve
+func (c *mockServerConfig) validate() []error {
+	var errs []error
+
+	if c.Logs == "" && c.HttpLogs == "" {
+		errs = append(errs, errors.New("At least one of Logs or HttpLogs is required, provide one of: --logs flag, --http-logs flag, RAILWAY_MOCK_LOGS environment variable or RAILWAY_MOCK_HTTP_LOGS environment variable"))
+	}
+
+	return errs
+}
+
+// ProjectWide reports if the logs of every service in the project are downloaded,
+// otherwise the project is only used to look up the environment and service by name
+func (c *config) ProjectWide() bool {
+	return c.ProjectID != "" && c.DeploymentID == "" && c.ServiceID == "" && c.PluginID == ""
+}
+
 // GetRequiredGroupValue returns the  value, and flag name for the field that is set in the specified required group
 func (c *config) GetRequiredGroupValue(groupName string) (flagName, value string) {
 	return parser.GetRequiredGroupValue(c, groupName)
 }
 
+// GetOneOfGroupValue returns the value, and flag name for the field that is set in the specified one_of group
+func (c *config) GetOneOfGroupValue(groupName string) (flagName, value string) {
+	return parser.GetOneOfGroupValue(c, groupName)
+}
+
 func (c *ConfigString) String() string {
 	return *(*string)(c)
 }
 
+func (c *ConfigString) Int() int {
+	i, _ := strconv.Atoi(*(*string)(c))
+
+	return i
+}
+
 func (c *ConfigString) Bool() bool {
 	b, _ := strconv.ParseBool(*(*string)(c))
 
 	return b
 }
+
+func (c *ConfigString) Duration() time.Duration {
+	d, _ := time.ParseDuration(*(*string)(c))
+
+	return d
+}
+
+// Codec returns the codec a log file is compressed with, an empty or invalid value leaves it uncompressed
+func (c *ConfigString) Codec() compression.Codec {
+	codec, _ := compression.Parse(*(*string)(c))
+
+	return codec
+}
+
+// Time returns the timestamp, relative durations are resolved against the start of the program,
+// an empty or invalid value returns the zero time
+func (c *ConfigString) Time() time.Time {
+	t, _ := parser.ParseTimestamp(*(*string)(c), startedAt)
+
+	return t
+}
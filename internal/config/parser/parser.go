@@ -3,10 +3,12 @@ package parser
 import (
 	"flag"
 	"fmt"
+	"net/url"
 	"os"
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/dustin/go-humanize/english"
 	"github.com/google/uuid"
@@ -96,12 +98,14 @@ func ParseConfig(cfg any) []error {
 
 	// Validate required groups
 	groups := make(map[string][]fieldInfo)
+	oneOfGroups := make(map[string][]fieldInfo)
 	requiredAllGroups := make(map[string][]fieldInfo)
 
 	for i := range t.NumField() {
 		field := t.Field(i)
 		fieldValue := v.Field(i)
 		requiredOneOfTag := field.Tag.Get("required_one_of")
+		oneOfTag := field.Tag.Get("one_of")
 		requiredAllTag := field.Tag.Get("required_all")
 
 		if requiredOneOfTag != "" {
@@ -114,6 +118,16 @@ func ParseConfig(cfg any) []error {
 			})
 		}
 
+		if oneOfTag != "" {
+			groupName := oneOfTag
+			oneOfGroups[groupName] = append(oneOfGroups[groupName], fieldInfo{
+				Name:     field.Name,
+				HasValue: fieldValue.String() != "",
+				FlagName: field.Tag.Get("flag"),
+				EnvVars:  strings.Split(field.Tag.Get("env"), ","),
+			})
+		}
+
 		if requiredAllTag != "" {
 			groupName := requiredAllTag
 			requiredAllGroups[groupName] = append(requiredAllGroups[groupName], fieldInfo{
@@ -158,6 +172,24 @@ func ParseConfig(cfg any) []error {
 		}
 	}
 
+	// Validate one_of groups, unlike required_one_of groups these can be left empty
+	for _, fields := range oneOfGroups {
+		fieldsWithValues := 0
+		var fieldNames []string
+
+		for _, field := range fields {
+			fieldNames = append(fieldNames, field.Name)
+
+			if field.HasValue {
+				fieldsWithValues++
+			}
+		}
+
+		if fieldsWithValues > 1 {
+			errors = append(errors, fmt.Errorf("Only one of %s can be provided, but multiple were set", english.WordSeries(fieldNames, "or")))
+		}
+	}
+
 	// Validate required_all groups
 	for _, fields := range requiredAllGroups {
 		fieldsWithValues := 0
@@ -211,6 +243,26 @@ func ParseConfig(cfg any) []error {
 					errors = append(errors, fmt.Errorf("%s: %s is not a valid boolean", field.Name, fieldValueStr))
 					continue
 				}
+			case "timestamp":
+				if _, err := ParseTimestamp(fieldValueStr, time.Now()); err != nil {
+					errors = append(errors, fmt.Errorf("%s: %s is %w, use a timestamp like 2025-01-02T15:04:05Z or a duration like 6h or 3d", field.Name, fieldValueStr, err))
+					continue
+				}
+			case "url":
+				if u, err := url.ParseRequestURI(fieldValueStr); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+					errors = append(errors, fmt.Errorf("%s: %s is not a valid url, use an http or https url like http://localhost:4000/graphql/v2", field.Name, fieldValueStr))
+					continue
+				}
+			case "duration":
+				if d, err := time.ParseDuration(fieldValueStr); err != nil || d <= 0 {
+					errors = append(errors, fmt.Errorf("%s: %s is not a valid duration, use a duration like 500ms, 2s or 1m", field.Name, fieldValueStr))
+					continue
+				}
+			case "positive_integer":
+				if i, err := strconv.Atoi(fieldValueStr); err != nil || i < 1 {
+					errors = append(errors, fmt.Errorf("%s: %s is not a valid positive integer", field.Name, fieldValueStr))
+					continue
+				}
 			default:
 				errors = append(errors, fmt.Errorf("%s: validate for type %s not implemented", field.Name, validate))
 				continue
@@ -243,6 +295,24 @@ func GetRequiredGroupValue(cfg any, groupName string) (flagName, value string) {
 	return "", ""
 }
 
+// GetOneOfGroupValue returns the value, and flag name for the field that is set in the specified one_of group
+func GetOneOfGroupValue(cfg any, groupName string) (flagName, value string) {
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		fieldValue := v.Field(i)
+		oneOfTag := field.Tag.Get("one_of")
+
+		if oneOfTag == groupName && fieldValue.String() != "" {
+			return field.Tag.Get("flag"), fieldValue.String()
+		}
+	}
+
+	return "", ""
+}
+
 // fieldInfo holds information about a field for group validation
 type fieldInfo struct {
 	Name     string
@@ -0,0 +1,52 @@
+package parser
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidTimestamp = errors.New("not a valid RFC3339 timestamp or relative duration")
+
+// relativeDurationRe matches durations like 30m, 6h, 3d or 1w2d12h
+var relativeDurationRe = regexp.MustCompile(`^(\d+(?:\.\d+)?(?:ms|s|m|h|d|w))+$`)
+
+// relativeDurationPartRe matches a single number and unit of a relative duration
+var relativeDurationPartRe = regexp.MustCompile(`(\d+(?:\.\d+)?)(ms|s|m|h|d|w)`)
+
+var relativeDurationUnits = map[string]time.Duration{
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+	"w":  7 * 24 * time.Hour,
+}
+
+// ParseTimestamp parses an RFC3339 timestamp, or a duration like 6h or 3d that is relative to now
+func ParseTimestamp(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if timestamp, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return timestamp.UTC(), nil
+	}
+
+	if !relativeDurationRe.MatchString(value) {
+		return time.Time{}, ErrInvalidTimestamp
+	}
+
+	duration := time.Duration(0)
+
+	for _, part := range relativeDurationPartRe.FindAllStringSubmatch(value, -1) {
+		amount, err := strconv.ParseFloat(part[1], 64)
+		if err != nil {
+			return time.Time{}, ErrInvalidTimestamp
+		}
+
+		duration += time.Duration(amount * float64(relativeDurationUnits[part[2]]))
+	}
+
+	return now.Add(-duration).UTC(), nil
+}
@@ -3,5 +3,7 @@ package logline
 import "errors"
 
 var (
-	ErrFailedToAppendToJSON = errors.New("failed to append attribute to json")
+	ErrFailedToAppendToJSON   = errors.New("failed to append attribute to json")
+	ErrFailedToMarshalHttpLog = errors.New("failed to marshal http log")
+	ErrFailedToParseLogLine   = errors.New("failed to parse log line")
 )
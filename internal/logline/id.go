@@ -0,0 +1,90 @@
+package logline
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/buger/jsonparser"
+)
+
+// ID_FIELD is the field the id of a log is written to
+const ID_FIELD = "_id"
+
+// LogID identifies a log by its content
+type LogID [16]byte
+
+func (id LogID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// HashLogLine hashes the timestamp, message, tags and attributes of a reconstructed log line, every field but the id itself is part of the hash,
+// the fields are hashed in a fixed order so the same log gets the same id no matter when or where it was downloaded.
+// The timestamp of the log is returned too, so the line doesn't have to be decoded again to find out when it was logged
+func HashLogLine(jsonObject []byte) (LogID, time.Time, error) {
+	decoder := json.NewDecoder(bytes.NewReader(jsonObject))
+
+	// numbers are kept as they were written, a float64 would round large integers
+	decoder.UseNumber()
+
+	fields := map[string]any{}
+
+	if err := decoder.Decode(&fields); err != nil {
+		return LogID{}, time.Time{}, fmt.Errorf("%w: %w", ErrFailedToParseLogLine, err)
+	}
+
+	timestamp, err := fieldTimestamp(fields)
+	if err != nil {
+		return LogID{}, time.Time{}, err
+	}
+
+	delete(fields, ID_FIELD)
+
+	canonical := bytes.Buffer{}
+
+	// maps are encoded with their keys sorted, and without escaping html so the hash is easy to reproduce elsewhere
+	encoder := json.NewEncoder(&canonical)
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(fields); err != nil {
+		return LogID{}, time.Time{}, fmt.Errorf("%w: %w", ErrFailedToParseLogLine, err)
+	}
+
+	sum := sha256.Sum256(bytes.TrimSuffix(canonical.Bytes(), []byte{'\n'}))
+
+	return LogID(sum[:16]), timestamp, nil
+}
+
+// fieldTimestamp parses the timestamp of a decoded log line, a line without one has the zero time
+func fieldTimestamp(fields map[string]any) (time.Time, error) {
+	value, ok := fields["timestamp"]
+	if !ok {
+		return time.Time{}, nil
+	}
+
+	rawTimestamp, ok := value.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("%w: the timestamp isn't a string", ErrFailedToParseLogLine)
+	}
+
+	timestamp, err := time.Parse(time.RFC3339Nano, rawTimestamp)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %w", ErrFailedToParseLogLine, err)
+	}
+
+	return timestamp, nil
+}
+
+// SetLogID adds the id to a reconstructed log line, replacing the id it already had
+func SetLogID(jsonObject []byte, id LogID) ([]byte, error) {
+	jsonObject, err := jsonparser.Set(jsonObject, []byte(strconv.Quote(id.String())), ID_FIELD)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToAppendToJSON, err)
+	}
+
+	return jsonObject, nil
+}
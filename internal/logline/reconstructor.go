@@ -1,6 +1,7 @@
 package logline
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
@@ -51,3 +52,53 @@ func ReconstructLogLine(log *railway.EnvironmentLogsEnvironmentLogsLog) (jsonObj
 
 	return jsonObject, nil
 }
+
+// reconstruct a single log into a raw json object that also keeps the tags identifying where the log came from
+func ReconstructLogLineWithTags(log *railway.EnvironmentLogsEnvironmentLogsLog) (jsonObject []byte, err error) {
+	jsonObject, err = ReconstructLogLine(log)
+	if err != nil {
+		return nil, err
+	}
+
+	if log.Tags == nil {
+		return jsonObject, nil
+	}
+
+	tags := []struct {
+		key   string
+		value string
+	}{
+		{"projectId", log.Tags.ProjectId},
+		{"environmentId", log.Tags.EnvironmentId},
+		{"serviceId", log.Tags.ServiceId},
+		{"deploymentId", log.Tags.DeploymentId},
+		{"deploymentInstanceId", log.Tags.DeploymentInstanceId},
+		{"pluginId", log.Tags.PluginId},
+		{"snapshotId", log.Tags.SnapshotId},
+	}
+
+	for _, tag := range tags {
+		// skip the tags that don't apply to this log
+		if tag.value == "" {
+			continue
+		}
+
+		jsonObject, err = jsonparser.Set(jsonObject, []byte(strconv.Quote(tag.value)), "tags", tag.key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrFailedToAppendToJSON, err)
+		}
+	}
+
+	return jsonObject, nil
+}
+
+// reconstruct a single http log into a raw json object
+func ReconstructHttpLogLine(log *railway.HttpLogsHttpLogsHttpLog) (jsonObject []byte, err error) {
+	// the generated struct already carries the json field names in query order, timestamp first
+	jsonObject, err = json.Marshal(log)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToMarshalHttpLog, err)
+	}
+
+	return jsonObject, nil
+}
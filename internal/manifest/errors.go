@@ -0,0 +1,10 @@
+package manifest
+
+import "errors"
+
+var (
+	ErrFailedToReadManifest  = errors.New("failed to read manifest")
+	ErrFailedToParseManifest = errors.New("failed to parse manifest")
+	ErrFailedToWriteManifest = errors.New("failed to write manifest")
+	ErrParametersMismatch    = errors.New("the log file was downloaded with different parameters")
+)
@@ -0,0 +1,145 @@
+package manifest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Status tells if a log file holds every log of its time range
+type Status string
+
+const (
+	// StatusComplete means every log from the start of the time range up to the newest log in the file was downloaded
+	StatusComplete Status = "complete"
+
+	// StatusIncomplete means the download stopped before it reached the start of the time range, resuming it downloads the rest
+	StatusIncomplete Status = "incomplete"
+)
+
+// Manifest describes what is in a log file and how it was downloaded, it is saved next to the log file as <file>.meta.json
+type Manifest struct {
+	ToolVersion string `json:"toolVersion"`
+
+	Query Query `json:"query"`
+
+	// Coverage is the time range of the logs in the file
+	Coverage Coverage `json:"coverage"`
+
+	Lines  int64  `json:"lines"`
+	Status Status `json:"status"`
+
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Query holds the parameters the logs were downloaded with, a log file can only be continued with the same ones
+type Query struct {
+	// Kind is the kind of logs and what they were downloaded for, e.g. deployment, service, environment, http or build
+	Kind string `json:"kind"`
+
+	ProjectId     string `json:"projectId,omitempty"`
+	EnvironmentId string `json:"environmentId,omitempty"`
+	ServiceId     string `json:"serviceId,omitempty"`
+	DeploymentId  string `json:"deploymentId,omitempty"`
+	PluginId      string `json:"pluginId,omitempty"`
+
+	Filter string `json:"filter"`
+
+	// Since and Until are the time range that was asked for, left out when that end of the range is open
+	Since *time.Time `json:"since,omitempty"`
+	Until *time.Time `json:"until,omitempty"`
+
+	// WithTags tells if every log keeps the tags of the service and deployment it came from
+	WithTags bool `json:"withTags"`
+}
+
+// Coverage is the time range between the oldest and the newest log in a file, left out when the file has no logs
+type Coverage struct {
+	Oldest *time.Time `json:"oldest,omitempty"`
+	Newest *time.Time `json:"newest,omitempty"`
+}
+
+// Path returns where the manifest of a log file is saved
+func Path(logFileName string) string {
+	return logFileName + ".meta.json"
+}
+
+// Read returns the manifest of a log file, nil when the log file doesn't have one
+func Read(logFileName string) (*Manifest, error) {
+	content, err := os.ReadFile(Path(logFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToReadManifest, err)
+	}
+
+	manifest := &Manifest{}
+
+	if err := json.Unmarshal(content, manifest); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToParseManifest, err)
+	}
+
+	return manifest, nil
+}
+
+// Write saves the manifest of a log file, it is written to a temporary file first so a crash can't leave half of it behind
+func Write(logFileName string, manifest Manifest) error {
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToWriteManifest, err)
+	}
+
+	tmpPath := Path(logFileName) + ".tmp"
+
+	if err := os.WriteFile(tmpPath, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToWriteManifest, err)
+	}
+
+	if err := os.Rename(tmpPath, Path(logFileName)); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToWriteManifest, err)
+	}
+
+	return nil
+}
+
+// Verify returns an error naming every parameter that is different from the ones the log file was downloaded with.
+// The time range isn't compared since continuing a log file is how another part of it is downloaded,
+// and an id is only compared when both are known since the project and environment aren't always needed to find a target
+func (q Query) Verify(previous Query) error {
+	mismatches := []string{}
+
+	for _, parameter := range []struct {
+		name     string
+		value    string
+		previous string
+		optional bool
+	}{
+		{"kind", q.Kind, previous.Kind, false},
+		{"project", q.ProjectId, previous.ProjectId, true},
+		{"environment", q.EnvironmentId, previous.EnvironmentId, true},
+		{"service", q.ServiceId, previous.ServiceId, true},
+		{"deployment", q.DeploymentId, previous.DeploymentId, true},
+		{"plugin", q.PluginId, previous.PluginId, true},
+		{"filter", q.Filter, previous.Filter, false},
+		{"tags", fmt.Sprint(q.WithTags), fmt.Sprint(previous.WithTags), false},
+	} {
+		if parameter.optional && (parameter.value == "" || parameter.previous == "") {
+			continue
+		}
+
+		if parameter.value != parameter.previous {
+			mismatches = append(mismatches, fmt.Sprintf("%s is %q but was %q", parameter.name, parameter.value, parameter.previous))
+		}
+	}
+
+	if len(mismatches) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrParametersMismatch, strings.Join(mismatches, ", "))
+}
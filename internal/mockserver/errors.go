@@ -0,0 +1,18 @@
+package mockserver
+
+import "errors"
+
+var (
+	ErrFailedToOpenFixture     = errors.New("failed to open fixture")
+	ErrFailedToParseFixture    = errors.New("failed to parse fixture")
+	ErrNoFixtures              = errors.New("at least one fixture is required")
+	ErrFailedToParseQuery      = errors.New("failed to parse query")
+	ErrOperationNotFound       = errors.New("operation not found")
+	ErrUnsupportedOperation    = errors.New("only queries are supported by the mock server")
+	ErrUnsupportedField        = errors.New("field is not supported by the mock server")
+	ErrMissingArgument         = errors.New("missing required argument")
+	ErrInvalidArgument         = errors.New("invalid argument")
+	ErrDeploymentNotFound      = errors.New("deployment not found")
+	ErrFailedToDecodeRequest   = errors.New("failed to decode request")
+	ErrFailedToProjectResponse = errors.New("failed to select the requested fields")
+)
@@ -0,0 +1,120 @@
+package mockserver
+
+import (
+	"strings"
+	"unicode"
+)
+
+// filterTerm is a single part of a filter, either @key:value or a word or "quoted phrase" the log has to contain
+type filterTerm struct {
+	key    string
+	value  string
+	negate bool
+}
+
+// filter is a parsed log filter, only the parts of Railway's filter syntax that are useful for trying out the downloader are supported:
+// @key:value matches a tag, attribute or http log field, any other term matches the message (or path of http logs),
+// terms prefixed with - must not match and every term has to match
+type filter []filterTerm
+
+// aliases of the filter keys that Railway accepts for the same field
+var filterKeyAliases = map[string]string{
+	"severity":           "level",
+	"deploymentId":       "deployment",
+	"serviceId":          "service",
+	"environmentId":      "environment",
+	"pluginId":           "plugin",
+	"deploymentInstance": "replica",
+}
+
+func parseFilter(filterString string) filter {
+	terms := filter{}
+
+	for _, token := range splitFilter(filterString) {
+		term := filterTerm{}
+
+		if strings.HasPrefix(token, "-") && len(token) > 1 {
+			term.negate = true
+			token = token[1:]
+		}
+
+		if key, value, ok := strings.Cut(token, ":"); ok && strings.HasPrefix(key, "@") && len(key) > 1 {
+			term.key = strings.TrimPrefix(key, "@")
+			term.value = strings.Trim(value, `"`)
+
+			if alias, ok := filterKeyAliases[term.key]; ok {
+				term.key = alias
+			}
+		} else {
+			term.value = strings.Trim(token, `"`)
+		}
+
+		terms = append(terms, term)
+	}
+
+	return terms
+}
+
+// splitFilter splits the filter on whitespace, keeping quoted phrases together
+func splitFilter(filterString string) []string {
+	tokens := []string{}
+	current := strings.Builder{}
+	quoted := false
+
+	for _, r := range filterString {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			current.WriteRune(r)
+		case unicode.IsSpace(r) && !quoted:
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+
+	return tokens
+}
+
+// match reports if the log matches every term of the filter
+func (f filter) match(fields map[string]string, text string) bool {
+	for _, term := range f {
+		if term.matches(fields, text) == term.negate {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (t filterTerm) matches(fields map[string]string, text string) bool {
+	if t.key == "" {
+		return strings.Contains(strings.ToLower(text), strings.ToLower(t.value))
+	}
+
+	value, ok := fields[t.key]
+
+	// fixtures without tags can't tell where a log came from, so they match every deployment, service and environment
+	if !ok || value == "" {
+		switch t.key {
+		case "deployment", "service", "environment", "plugin", "replica":
+			return true
+		}
+
+		return false
+	}
+
+	// levels are matched loosely since both error and err are used
+	if t.key == "level" {
+		return strings.HasPrefix(strings.ToLower(value), strings.ToLower(t.value)) || strings.HasPrefix(strings.ToLower(t.value), strings.ToLower(value))
+	}
+
+	return strings.EqualFold(value, t.value)
+}
@@ -0,0 +1,196 @@
+package mockserver
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"main/internal/compression"
+	"main/internal/railway"
+
+	"github.com/buger/jsonparser"
+)
+
+// entry is a single log of a fixture together with its parsed timestamp
+type entry[T any] struct {
+	timestamp time.Time
+	log       T
+
+	// the fields the filter can match on, keyed by the name used in the filter syntax
+	fields map[string]string
+	text   string
+}
+
+// loadLogs reads a fixture in the format the log downloader writes deployment and environment logs in,
+// the level, message, timestamp and tags are turned back into their log fields and everything else becomes an attribute
+func loadLogs(filename string) ([]entry[*railway.EnvironmentLogsEnvironmentLogsLog], error) {
+	return loadFixture(filename, parseLogLine)
+}
+
+// loadHttpLogs reads a fixture in the format the log downloader writes http logs in
+func loadHttpLogs(filename string) ([]entry[*railway.HttpLogsHttpLogsHttpLog], error) {
+	return loadFixture(filename, parseHttpLogLine)
+}
+
+// loadFixture parses every line of the file and sorts the logs oldest first, the same order the api keeps them in
+func loadFixture[T any](filename string, parse func(line []byte) (entry[T], error)) ([]entry[T], error) {
+	file, err := compression.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToOpenFixture, err)
+	}
+
+	defer file.Close()
+
+	entries := []entry[T]{}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		parsed, err := parse(scanner.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s line %d: %w", ErrFailedToParseFixture, filename, lineNumber, err)
+		}
+
+		entries = append(entries, parsed)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToParseFixture, err)
+	}
+
+	slices.SortStableFunc(entries, func(a, b entry[T]) int {
+		return a.timestamp.Compare(b.timestamp)
+	})
+
+	return entries, nil
+}
+
+func parseLogLine(line []byte) (entry[*railway.EnvironmentLogsEnvironmentLogsLog], error) {
+	log := &railway.EnvironmentLogsEnvironmentLogsLog{
+		Attributes: []*railway.EnvironmentLogsEnvironmentLogsLogAttributesLogAttribute{},
+	}
+
+	fields := map[string]string{}
+
+	err := jsonparser.ObjectEach(line, func(key []byte, value []byte, dataType jsonparser.ValueType, _ int) error {
+		switch string(key) {
+		case "timestamp":
+			log.Timestamp = string(value)
+		case "message":
+			log.Message = stringValue(value, dataType)
+		case "level":
+			log.Severity = stringValue(value, dataType)
+		case "tags":
+			tags := &railway.EnvironmentLogsEnvironmentLogsLogTags{}
+
+			if err := json.Unmarshal(value, tags); err != nil {
+				return err
+			}
+
+			log.Tags = tags
+		default:
+			// attribute values are kept as raw json, the same way the api returns them,
+			// strings are handed over without their quotes but still escaped
+			rawValue := string(value)
+
+			if dataType == jsonparser.String {
+				rawValue = `"` + rawValue + `"`
+			}
+
+			log.Attributes = append(log.Attributes, &railway.EnvironmentLogsEnvironmentLogsLogAttributesLogAttribute{
+				Key:   string(key),
+				Value: rawValue,
+			})
+
+			fields[string(key)] = stringValue(value, dataType)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return entry[*railway.EnvironmentLogsEnvironmentLogsLog]{}, err
+	}
+
+	timestamp, err := time.Parse(time.RFC3339Nano, log.Timestamp)
+	if err != nil {
+		return entry[*railway.EnvironmentLogsEnvironmentLogsLog]{}, err
+	}
+
+	// the api returns the level as an attribute too
+	if log.Severity != "" {
+		log.Attributes = append([]*railway.EnvironmentLogsEnvironmentLogsLogAttributesLogAttribute{{
+			Key:   "level",
+			Value: strconv.Quote(log.Severity),
+		}}, log.Attributes...)
+	}
+
+	fields["level"] = log.Severity
+
+	if log.Tags != nil {
+		fields["deployment"] = log.Tags.DeploymentId
+		fields["service"] = log.Tags.ServiceId
+		fields["environment"] = log.Tags.EnvironmentId
+		fields["plugin"] = log.Tags.PluginId
+		fields["replica"] = log.Tags.DeploymentInstanceId
+	}
+
+	return entry[*railway.EnvironmentLogsEnvironmentLogsLog]{
+		timestamp: timestamp,
+		log:       log,
+		fields:    fields,
+		text:      log.Message,
+	}, nil
+}
+
+func parseHttpLogLine(line []byte) (entry[*railway.HttpLogsHttpLogsHttpLog], error) {
+	log := &railway.HttpLogsHttpLogsHttpLog{}
+
+	if err := json.Unmarshal(line, log); err != nil {
+		return entry[*railway.HttpLogsHttpLogsHttpLog]{}, err
+	}
+
+	timestamp, err := time.Parse(time.RFC3339Nano, log.Timestamp)
+	if err != nil {
+		return entry[*railway.HttpLogsHttpLogsHttpLog]{}, err
+	}
+
+	fields := map[string]string{}
+
+	err = jsonparser.ObjectEach(line, func(key []byte, value []byte, dataType jsonparser.ValueType, _ int) error {
+		fields[string(key)] = stringValue(value, dataType)
+		return nil
+	})
+	if err != nil {
+		return entry[*railway.HttpLogsHttpLogsHttpLog]{}, err
+	}
+
+	return entry[*railway.HttpLogsHttpLogsHttpLog]{
+		timestamp: timestamp,
+		log:       log,
+		fields:    fields,
+		text:      log.Path,
+	}, nil
+}
+
+// stringValue returns json strings without their quotes and escapes, and every other value as it is
+func stringValue(value []byte, dataType jsonparser.ValueType) string {
+	if dataType == jsonparser.String {
+		if unescaped, err := jsonparser.ParseString(value); err == nil {
+			return unescaped
+		}
+	}
+
+	return string(value)
+}
@@ -0,0 +1,360 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"main/internal/railway"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+const (
+	GRAPHQL_PATH = "/graphql/v2"
+
+	// returned as the environment of every deployment when the fixture doesn't have any tags to look it up in
+	MOCK_ENVIRONMENT_ID = "00000000-0000-0000-0000-000000000000"
+)
+
+// Server is a stand-in for the Railway API that answers the environmentLogs, httpLogs and deployment queries
+// from local fixtures, so the log downloader can be tried out without a token or a network connection
+type Server struct {
+	logs     []entry[*railway.EnvironmentLogsEnvironmentLogsLog]
+	httpLogs []entry[*railway.HttpLogsHttpLogsHttpLog]
+
+	// deployments are looked up in the tags of the logs, fixtures without tags can't tell them apart
+	deployments map[string]*railway.EnvironmentLogsEnvironmentLogsLogTags
+	tagged      bool
+}
+
+type graphqlRequest struct {
+	Query         string         `json:"query"`
+	Variables     map[string]any `json:"variables"`
+	OperationName string         `json:"operationName"`
+}
+
+type graphqlError struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path,omitempty"`
+}
+
+type graphqlResponse struct {
+	Data   map[string]any `json:"data"`
+	Errors []graphqlError `json:"errors,omitempty"`
+}
+
+// New loads the fixtures, either fixture can be left empty but not both
+func New(logsFixture string, httpLogsFixture string) (*Server, error) {
+	if logsFixture == "" && httpLogsFixture == "" {
+		return nil, ErrNoFixtures
+	}
+
+	server := &Server{
+		deployments: map[string]*railway.EnvironmentLogsEnvironmentLogsLogTags{},
+	}
+
+	if logsFixture != "" {
+		logs, err := loadLogs(logsFixture)
+		if err != nil {
+			return nil, err
+		}
+
+		server.logs = logs
+	}
+
+	if httpLogsFixture != "" {
+		httpLogs, err := loadHttpLogs(httpLogsFixture)
+		if err != nil {
+			return nil, err
+		}
+
+		server.httpLogs = httpLogs
+	}
+
+	for _, log := range server.logs {
+		if log.log.Tags != nil && log.log.Tags.DeploymentId != "" {
+			server.deployments[log.log.Tags.DeploymentId] = log.log.Tags
+			server.tagged = true
+		}
+	}
+
+	return server, nil
+}
+
+// Stats returns the number of logs and http logs the server serves
+func (s *Server) Stats() (logs int, httpLogs int) {
+	return len(s.logs), len(s.httpLogs)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "only POST requests are supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	request := graphqlRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeResponse(w, http.StatusBadRequest, graphqlResponse{Errors: []graphqlError{{Message: fmt.Errorf("%w: %w", ErrFailedToDecodeRequest, err).Error()}}})
+		return
+	}
+
+	response, err := s.execute(request, time.Now().UTC())
+	if err != nil {
+		writeResponse(w, http.StatusBadRequest, graphqlResponse{Errors: []graphqlError{{Message: err.Error()}}})
+		return
+	}
+
+	writeResponse(w, http.StatusOK, response)
+}
+
+// execute resolves every top level field of the operation, a field that fails is returned as null with an error
+func (s *Server) execute(request graphqlRequest, now time.Time) (graphqlResponse, error) {
+	document, err := parser.ParseQuery(&ast.Source{Input: request.Query})
+	if err != nil {
+		return graphqlResponse{}, fmt.Errorf("%w: %w", ErrFailedToParseQuery, err)
+	}
+
+	var operation *ast.OperationDefinition
+
+	switch {
+	case request.OperationName != "":
+		operation = document.Operations.ForName(request.OperationName)
+	case len(document.Operations) == 1:
+		operation = document.Operations[0]
+	}
+
+	if operation == nil {
+		return graphqlResponse{}, fmt.Errorf("%w: %s", ErrOperationNotFound, request.OperationName)
+	}
+
+	if operation.Operation != ast.Query {
+		return graphqlResponse{}, ErrUnsupportedOperation
+	}
+
+	response := graphqlResponse{Data: map[string]any{}}
+
+	for _, selection := range operation.SelectionSet {
+		field, ok := selection.(*ast.Field)
+		if !ok {
+			continue
+		}
+
+		responseKey := field.Alias
+		if responseKey == "" {
+			responseKey = field.Name
+		}
+
+		value, err := s.resolve(field, request.Variables, now)
+		if err == nil {
+			value, err = project(value, field.SelectionSet)
+		}
+
+		if err != nil {
+			response.Data[responseKey] = nil
+			response.Errors = append(response.Errors, graphqlError{Message: err.Error(), Path: []any{responseKey}})
+
+			continue
+		}
+
+		response.Data[responseKey] = value
+	}
+
+	return response, nil
+}
+
+func (s *Server) resolve(field *ast.Field, variables map[string]any, now time.Time) (any, error) {
+	args, err := arguments(field, variables)
+	if err != nil {
+		return nil, err
+	}
+
+	switch field.Name {
+	case "__typename":
+		return "Query", nil
+	case "environmentLogs":
+		environmentId := argString(args, "environmentId")
+		if environmentId == "" {
+			return nil, fmt.Errorf("%w: environmentId", ErrMissingArgument)
+		}
+
+		window, err := parseWindowArgs(args, now)
+		if err != nil {
+			return nil, err
+		}
+
+		logFilter := append(parseFilter(argString(args, "filter")), filterTerm{key: "environment", value: environmentId})
+
+		return selectWindow(s.logs, window, logFilter), nil
+	case "httpLogs":
+		deploymentId := argString(args, "deploymentId")
+		if deploymentId == "" {
+			return nil, fmt.Errorf("%w: deploymentId", ErrMissingArgument)
+		}
+
+		window, err := parseWindowArgs(args, now)
+		if err != nil {
+			return nil, err
+		}
+
+		logFilter := append(parseFilter(argString(args, "filter")), filterTerm{key: "deploymentId", value: deploymentId})
+
+		return selectWindow(s.httpLogs, window, logFilter), nil
+	case "deployment":
+		return s.deployment(argString(args, "id"))
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedField, field.Name)
+}
+
+// deployment looks up where a deployment belongs in the tags of the logs
+func (s *Server) deployment(id string) (any, error) {
+	if id == "" {
+		return nil, fmt.Errorf("%w: id", ErrMissingArgument)
+	}
+
+	tags, ok := s.deployments[id]
+
+	if !ok {
+		if s.tagged {
+			return nil, ErrDeploymentNotFound
+		}
+
+		tags = &railway.EnvironmentLogsEnvironmentLogsLogTags{
+			DeploymentId:  id,
+			EnvironmentId: MOCK_ENVIRONMENT_ID,
+			ProjectId:     MOCK_ENVIRONMENT_ID,
+		}
+	}
+
+	return map[string]any{
+		"id":            id,
+		"environmentId": tags.EnvironmentId,
+		"projectId":     tags.ProjectId,
+		"serviceId":     tags.ServiceId,
+	}, nil
+}
+
+// project keeps only the fields that were selected, the same way a graphql server would
+func project(value any, selectionSet ast.SelectionSet) (any, error) {
+	if len(selectionSet) == 0 || value == nil {
+		return value, nil
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToProjectResponse, err)
+	}
+
+	var decoded any
+
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToProjectResponse, err)
+	}
+
+	return selectFields(decoded, selectionSet), nil
+}
+
+func selectFields(value any, selectionSet ast.SelectionSet) any {
+	switch value := value.(type) {
+	case []any:
+		for i := range value {
+			value[i] = selectFields(value[i], selectionSet)
+		}
+
+		return value
+	case map[string]any:
+		selected := map[string]any{}
+
+		for _, selection := range selectionSet {
+			field, ok := selection.(*ast.Field)
+			if !ok {
+				continue
+			}
+
+			responseKey := field.Alias
+			if responseKey == "" {
+				responseKey = field.Name
+			}
+
+			selected[responseKey] = selectFields(value[field.Name], field.SelectionSet)
+		}
+
+		return selected
+	}
+
+	return value
+}
+
+// arguments resolves the arguments of the field, variables that weren't provided are left out
+func arguments(field *ast.Field, variables map[string]any) (map[string]any, error) {
+	args := map[string]any{}
+
+	for _, argument := range field.Arguments {
+		value, err := argument.Value.Value(variables)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidArgument, argument.Name, err)
+		}
+
+		if value != nil {
+			args[argument.Name] = value
+		}
+	}
+
+	return args, nil
+}
+
+func argString(args map[string]any, name string) string {
+	value, _ := args[name].(string)
+
+	return value
+}
+
+// argInt returns the integer argument, json numbers in variables are decoded as floats while literals are parsed as ints
+func argInt(args map[string]any, name string) (int, bool, error) {
+	switch value := args[name].(type) {
+	case nil:
+		return 0, false, nil
+	case int64:
+		return int(value), true, nil
+	case float64:
+		return int(value), true, nil
+	case string:
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, false, fmt.Errorf("%w: %s: %w", ErrInvalidArgument, name, err)
+		}
+
+		return i, true, nil
+	}
+
+	return 0, false, fmt.Errorf("%w: %s is not an integer", ErrInvalidArgument, name)
+}
+
+// argTime returns the date argument, an empty date is treated the same as a missing one
+func argTime(args map[string]any, name string) (time.Time, error) {
+	value := argString(args, name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	timestamp, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s: %w", ErrInvalidArgument, name, err)
+	}
+
+	return timestamp, nil
+}
+
+func writeResponse(w http.ResponseWriter, statusCode int, response graphqlResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	// the status is already written so a failed write can only mean the client went away
+	json.NewEncoder(w).Encode(response)
+}
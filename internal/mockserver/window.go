@@ -0,0 +1,114 @@
+package mockserver
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+var (
+	MAX_LIMIT     = 5000 // the most logs the api returns on either side of the anchor
+	DEFAULT_LIMIT = 100  // used when neither limit is provided
+)
+
+// windowArgs are the pagination arguments environmentLogs and httpLogs share
+type windowArgs struct {
+	// the logs are split at the anchor, it defaults to now
+	anchorDate time.Time
+
+	// the oldest log to return before the anchor and the newest log to return after it, zero leaves them open
+	beforeDate time.Time
+	afterDate  time.Time
+
+	// the number of logs to return on either side of the anchor
+	beforeLimit int
+	afterLimit  int
+}
+
+func parseWindowArgs(args map[string]any, now time.Time) (windowArgs, error) {
+	window := windowArgs{}
+
+	var err error
+
+	if window.anchorDate, err = argTime(args, "anchorDate"); err != nil {
+		return window, err
+	}
+
+	if window.beforeDate, err = argTime(args, "beforeDate"); err != nil {
+		return window, err
+	}
+
+	if window.afterDate, err = argTime(args, "afterDate"); err != nil {
+		return window, err
+	}
+
+	beforeLimit, hasBeforeLimit, err := argInt(args, "beforeLimit")
+	if err != nil {
+		return window, err
+	}
+
+	afterLimit, hasAfterLimit, err := argInt(args, "afterLimit")
+	if err != nil {
+		return window, err
+	}
+
+	if window.anchorDate.IsZero() {
+		window.anchorDate = now
+	}
+
+	// without any limit the newest logs before the anchor are returned
+	if !hasBeforeLimit && !hasAfterLimit {
+		beforeLimit = DEFAULT_LIMIT
+	}
+
+	if beforeLimit < 0 || afterLimit < 0 {
+		return window, fmt.Errorf("%w: limits can't be negative", ErrInvalidArgument)
+	}
+
+	window.beforeLimit = min(beforeLimit, MAX_LIMIT)
+	window.afterLimit = min(afterLimit, MAX_LIMIT)
+
+	return window, nil
+}
+
+// selectWindow returns the logs around the anchor the way the api does, oldest first:
+// the beforeLimit newest logs at or before the anchor that aren't older than beforeDate,
+// followed by the afterLimit oldest logs after the anchor that aren't newer than afterDate
+func selectWindow[T any](entries []entry[T], window windowArgs, logFilter filter) []T {
+	// the first log after the anchor
+	split, _ := slices.BinarySearchFunc(entries, window.anchorDate, func(e entry[T], anchor time.Time) int {
+		if e.timestamp.After(anchor) {
+			return 1
+		}
+
+		return -1
+	})
+
+	before := []T{}
+
+	for i := split - 1; i >= 0 && len(before) < window.beforeLimit; i-- {
+		if !window.beforeDate.IsZero() && entries[i].timestamp.Before(window.beforeDate) {
+			break
+		}
+
+		if logFilter.match(entries[i].fields, entries[i].text) {
+			before = append(before, entries[i].log)
+		}
+	}
+
+	slices.Reverse(before)
+
+	after := []T{}
+
+	for i := split; i < len(entries) && len(after) < window.afterLimit; i++ {
+		if !window.afterDate.IsZero() && entries[i].timestamp.After(window.afterDate) {
+			break
+		}
+
+		if logFilter.match(entries[i].fields, entries[i].text) {
+			after = append(after, entries[i].log)
+		}
+	}
+
+	return append(before, after...)
+}
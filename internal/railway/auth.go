@@ -0,0 +1,85 @@
+package railway
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// TokenKind decides which header the token is sent in
+type TokenKind string
+
+const (
+	TokenKindAccount TokenKind = "account"
+	TokenKindTeam    TokenKind = "team"
+	TokenKindProject TokenKind = "project"
+)
+
+// Credentials are the token that requests are authenticated with, an empty token sends no credentials at all
+type Credentials struct {
+	Token string
+	Kind  TokenKind
+}
+
+// setHeaders adds the token to the request headers, project tokens have their own header while
+// account and team tokens are both sent as bearer tokens
+func (c Credentials) setHeaders(header http.Header) {
+	switch {
+	case c.Token == "":
+		return
+	case c.Kind == TokenKindProject:
+		header.Set("Project-Access-Token", c.Token)
+	default:
+		header.Set("Authorization", "Bearer "+c.Token)
+	}
+}
+
+// DetectTokenKind asks the api what kind of token it is, only a project token can see the project token it is
+// and only an account token belongs to a user. A token that is neither is a team token once it can list projects
+func DetectTokenKind(ctx context.Context, endpoint string, token string) (TokenKind, error) {
+	_, err := ProjectToken(ctx, NewAuthedClient(endpoint, Credentials{Token: token, Kind: TokenKindProject}))
+	if err == nil {
+		return TokenKindProject, nil
+	}
+
+	if inconclusive(err) {
+		return "", fmt.Errorf("%w: %w", ErrFailedToDetectTokenKind, err)
+	}
+
+	_, err = Me(ctx, NewAuthedClient(endpoint, Credentials{Token: token, Kind: TokenKindAccount}))
+	if err == nil {
+		return TokenKindAccount, nil
+	}
+
+	if inconclusive(err) {
+		return "", fmt.Errorf("%w: %w", ErrFailedToDetectTokenKind, err)
+	}
+
+	// a token that isn't valid at all fails every query
+	_, err = TeamProjects(ctx, NewAuthedClient(endpoint, Credentials{Token: token, Kind: TokenKindTeam}))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrFailedToDetectTokenKind, err)
+	}
+
+	return TokenKindTeam, nil
+}
+
+// inconclusive reports if the error says nothing about the token, any other error means the token isn't of the kind that was tried
+func inconclusive(err error) bool {
+	switch ClassifyError(err) {
+	case ErrorClassTransientNetwork, ErrorClassServer, ErrorClassRateLimit:
+		return true
+	}
+
+	return false
+}
+
+// GetProjectTokenScope returns the project and environment a project token belongs to, it can't access anything else
+func GetProjectTokenScope(ctx context.Context, railwayClient *RailwayClient) (projectId string, environmentId string, err error) {
+	projectTokenResponse, err := ProjectToken(ctx, railwayClient)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %w", ErrFailedToGetProjectToken, err)
+	}
+
+	return projectTokenResponse.ProjectToken.ProjectId, projectTokenResponse.ProjectToken.EnvironmentId, nil
+}
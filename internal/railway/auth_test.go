@@ -0,0 +1,81 @@
+package railway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newAuthStandIn answers the operations in allowed and fails every other one the way the api fails a query the token can't run
+func newAuthStandIn(t *testing.T, allowed map[string]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := struct {
+			OperationName string `json:"operationName"`
+		}{}
+
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("failed to decode the request: %s", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if data, ok := allowed[request.OperationName]; ok {
+			w.Write([]byte(`{"data":` + data + `}`))
+			return
+		}
+
+		w.Write([]byte(`{"data":null,"errors":[{"message":"Not Authorized"}]}`))
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestDetectTokenKind(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed map[string]string
+		want    TokenKind
+	}{
+		{
+			name:    "project token",
+			allowed: map[string]string{"ProjectToken": `{"projectToken":{"projectId":"p","environmentId":"e"}}`},
+			want:    TokenKindProject,
+		},
+		{
+			name:    "account token",
+			allowed: map[string]string{"Me": `{"me":{"id":"u"}}`, "TeamProjects": `{"projects":{"edges":[]}}`},
+			want:    TokenKindAccount,
+		},
+		{
+			name:    "team token",
+			allowed: map[string]string{"TeamProjects": `{"projects":{"edges":[]}}`},
+			want:    TokenKindTeam,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kind, err := DetectTokenKind(context.Background(), newAuthStandIn(t, test.allowed).URL, "token")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if kind != test.want {
+				t.Fatalf("expected a %s token, got %s", test.want, kind)
+			}
+		})
+	}
+}
+
+func TestDetectTokenKindRejectsInvalidTokens(t *testing.T) {
+	kind, err := DetectTokenKind(context.Background(), newAuthStandIn(t, nil).URL, "token")
+	if !errors.Is(err, ErrFailedToDetectTokenKind) {
+		t.Fatalf("expected a token that fails every query to not be taken for a team token, got %q (%v)", kind, err)
+	}
+}
@@ -0,0 +1,38 @@
+package railway
+
+import (
+	"context"
+	"time"
+)
+
+func GetAllBuildLogsBlocking(ctx context.Context, railwayClient *RailwayClient, logs chan<- LogLinesResponse, options GetLogsOptions) error {
+	if options.DeploymentId == "" {
+		return ErrDeploymentIdRequired
+	}
+
+	return getAllWindowedLogsBlocking(ctx, logs, options, func(ctx context.Context, endDate time.Time, limit int) ([]*EnvironmentLogsEnvironmentLogsLog, error) {
+		logsResponse, err := BuildLogs(ctx, railwayClient,
+			options.DeploymentId, // deployment id
+			endDate,              // end date
+			options.Filter,       // filter
+			limit,                // limit
+			options.startDate(),  // start date (Unix epoch unless --since is set)
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		return logsResponse.BuildLogs, nil
+	})
+}
+
+func GetAllBuildLogsAsync(ctx context.Context, railwayClient *RailwayClient, logs chan<- LogLinesResponse, options GetLogsOptions) {
+	go func() {
+		if err := GetAllBuildLogsBlocking(ctx, railwayClient, logs, options); err != nil {
+			options.ErrorChannel <- err
+			return
+		}
+
+		options.DoneChannel <- true
+	}()
+}
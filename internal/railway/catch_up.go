@@ -0,0 +1,45 @@
+package railway
+
+import "context"
+
+// GetAllDeploymentLogsCatchUpBlocking fetches the logs of a deployment, service or environment that are newer than options.CatchUpFromTimestamp,
+// unlike GetAllDeploymentLogsBlocking the logs are fetched oldest first so they can be appended to the existing log file
+func GetAllDeploymentLogsCatchUpBlocking(ctx context.Context, railwayClient *RailwayClient, logs chan<- LogLinesResponse, options GetLogsOptions) error {
+	environmentId, filter, err := resolveEnvironmentLogsFilter(ctx, railwayClient, options)
+	if err != nil {
+		return err
+	}
+
+	return paginateForwards(ctx, options, environmentLogsQuery(railwayClient, options, environmentId, filter), sendEnvironmentLogs(logs, options.Shard))
+}
+
+func GetAllDeploymentLogsCatchUpAsync(ctx context.Context, railwayClient *RailwayClient, logs chan<- LogLinesResponse, options GetLogsOptions) {
+	go func() {
+		if err := GetAllDeploymentLogsCatchUpBlocking(ctx, railwayClient, logs, options); err != nil {
+			options.ErrorChannel <- err
+			return
+		}
+
+		options.DoneChannel <- true
+	}()
+}
+
+// GetAllHttpLogsCatchUpBlocking fetches the http logs of a deployment that are newer than options.CatchUpFromTimestamp, oldest first
+func GetAllHttpLogsCatchUpBlocking(ctx context.Context, railwayClient *RailwayClient, logs chan<- LogLinesResponse, options GetLogsOptions) error {
+	if options.DeploymentId == "" {
+		return ErrDeploymentIdRequired
+	}
+
+	return paginateForwards(ctx, options, httpLogsQuery(railwayClient, options), sendHttpLogs(logs, options.Shard))
+}
+
+func GetAllHttpLogsCatchUpAsync(ctx context.Context, railwayClient *RailwayClient, logs chan<- LogLinesResponse, options GetLogsOptions) {
+	go func() {
+		if err := GetAllHttpLogsCatchUpBlocking(ctx, railwayClient, logs, options); err != nil {
+			options.ErrorChannel <- err
+			return
+		}
+
+		options.DoneChannel <- true
+	}()
+}
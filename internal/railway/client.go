@@ -5,6 +5,7 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/Khan/genqlient/graphql"
 	"github.com/buger/jsonparser"
@@ -12,17 +13,27 @@ import (
 	_ "github.com/Khan/genqlient/generate"
 )
 
+const (
+	API_ENDPOINT       = "https://backboard.railway.com/graphql/v2"
+	WEBSOCKET_ENDPOINT = "wss://backboard.railway.com/graphql/v2"
+)
+
 type authedTransport struct {
-	token   string
-	wrapped http.RoundTripper
+	credentials Credentials
+	wrapped     http.RoundTripper
+
+	rateLimiter *rateLimiter
 }
 
 type RailwayClient struct {
 	graphql.Client
+
+	credentials Credentials
+	rateLimiter *rateLimiter
 }
 
 func (t *authedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	req.Header.Set("Authorization", "Bearer "+t.token)
+	t.credentials.setHeaders(req.Header)
 	req.Header.Set("Content-Type", "application/json")
 
 	body, err := io.ReadAll(req.Body)
@@ -43,20 +54,81 @@ func (t *authedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 		req.URL.RawQuery = params.Encode()
 	}
 
-	req.Body = io.NopCloser(bytes.NewBuffer(body))
+	// a rate limited request is sent again once the quota has reset, no matter how long that takes
+	for {
+		if err := t.rateLimiter.wait(req.Context()); err != nil {
+			return nil, err
+		}
+
+		req.Body = io.NopCloser(bytes.NewBuffer(body))
+
+		resp, err := t.wrapped.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+
+		limited, err := t.rateLimiter.observe(resp)
+		if err != nil {
+			return nil, err
+		}
 
-	return t.wrapped.RoundTrip(req)
+		if !limited {
+			return resp, nil
+		}
+
+		resp.Body.Close()
+	}
 }
 
-func NewAuthedClient(token string) *RailwayClient {
+// NewAuthedClient creates a client for the graphql endpoint, an empty endpoint uses the railway api
+func NewAuthedClient(endpoint string, credentials Credentials) *RailwayClient {
+	if endpoint == "" {
+		endpoint = API_ENDPOINT
+	}
+
+	rateLimiter := &rateLimiter{}
+
 	httpClient := http.Client{
 		Transport: &authedTransport{
-			token:   token,
-			wrapped: http.DefaultTransport,
+			credentials: credentials,
+			wrapped:     http.DefaultTransport,
+			rateLimiter: rateLimiter,
 		},
 	}
 
 	return &RailwayClient{
-		graphql.NewClient("https://backboard.railway.com/graphql/v2", &httpClient),
+		Client:      graphql.NewClient(endpoint, &httpClient),
+		credentials: credentials,
+		rateLimiter: rateLimiter,
 	}
 }
+
+// Credentials returns the credentials the client authenticates with, so a websocket client can use the same ones
+func (c *RailwayClient) Credentials() Credentials {
+	return c.credentials
+}
+
+// WebSocketEndpoint returns the websocket endpoint that belongs to the graphql endpoint, an empty endpoint uses the railway api
+func WebSocketEndpoint(endpoint string) string {
+	if endpoint == "" {
+		return WEBSOCKET_ENDPOINT
+	}
+
+	if after, ok := strings.CutPrefix(endpoint, "https://"); ok {
+		return "wss://" + after
+	}
+
+	if after, ok := strings.CutPrefix(endpoint, "http://"); ok {
+		return "ws://" + after
+	}
+
+	return endpoint
+}
+
+// OnRateLimit sets a function that is called whenever the requests are held back until the rate limit quota resets
+func (c *RailwayClient) OnRateLimit(onRateLimit func(resetAt time.Time)) {
+	c.rateLimiter.mu.Lock()
+	defer c.rateLimiter.mu.Unlock()
+
+	c.rateLimiter.onRateLimit = onRateLimit
+}
@@ -5,8 +5,29 @@ import "errors"
 var (
 	ErrFilterIDRequired       = errors.New("filter id is required")
 	ErrDeploymentIdRequired   = errors.New("deployment id is required")
+	ErrPluginIdRequired       = errors.New("plugin id is required")
+	ErrEnvironmentIdRequired  = errors.New("environment id is required")
+	ErrProjectIdRequired      = errors.New("project id is required")
+	ErrFailedToGetProject     = errors.New("failed to get project data")
 	ErrFailedToGetDeployment  = errors.New("failed to get deployment data")
 	ErrFailedToGetLogs        = errors.New("failed to get logs")
 	ErrNoLogsFound            = errors.New("no logs found")
 	ErrFailedToParseTimestamp = errors.New("failed to parse timestamp")
+	ErrCatchUpFromRequired    = errors.New("timestamp to catch up from is required")
+	ErrBurstTooLarge          = errors.New("more logs share a single timestamp than can be paged through, logs at it would be lost")
+
+	ErrFailedToDetectTokenKind = errors.New("failed to detect the kind of token")
+	ErrFailedToGetProjectToken = errors.New("failed to get project token data")
+
+	ErrFailedToListProjects = errors.New("failed to list projects")
+	ErrProjectNotFound      = errors.New("project not found")
+	ErrEnvironmentNotFound  = errors.New("environment not found")
+	ErrServiceNotFound      = errors.New("service not found")
+	ErrAmbiguousProject     = errors.New("project name is ambiguous")
+	ErrAmbiguousEnvironment = errors.New("environment name is ambiguous")
+	ErrAmbiguousService     = errors.New("service name is ambiguous")
+
+	ErrFailedToConnectWebSocket = errors.New("failed to connect to websocket")
+	ErrWebSocketConnectionLost  = errors.New("websocket connection lost")
+	ErrWebSocketNotStarted      = errors.New("websocket client not started")
 )
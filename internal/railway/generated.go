@@ -4,10 +4,22 @@ package railway
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"time"
 
 	"github.com/Khan/genqlient/graphql"
 )
 
+// BuildLogsResponse is returned by BuildLogs on success.
+type BuildLogsResponse struct {
+	// Fetch logs for a build
+	BuildLogs []*EnvironmentLogsEnvironmentLogsLog `json:"buildLogs"`
+}
+
+// GetBuildLogs returns BuildLogsResponse.BuildLogs, and is useful for accessing the field via an interface.
+func (v *BuildLogsResponse) GetBuildLogs() []*EnvironmentLogsEnvironmentLogsLog { return v.BuildLogs }
+
 // DeploymentDeployment includes the requested fields of the GraphQL type Deployment.
 type DeploymentDeployment struct {
 	EnvironmentId string `json:"environmentId"`
@@ -29,6 +41,17 @@ type DeploymentResponse struct {
 // GetDeployment returns DeploymentResponse.Deployment, and is useful for accessing the field via an interface.
 func (v *DeploymentResponse) GetDeployment() *DeploymentDeployment { return v.Deployment }
 
+// EnvironmentLogsAfterResponse is returned by EnvironmentLogsAfter on success.
+type EnvironmentLogsAfterResponse struct {
+	// Fetch logs for a project environment. Build logs are excluded unless a snapshot ID is explicitly provided in the filter
+	EnvironmentLogs []*EnvironmentLogsEnvironmentLogsLog `json:"environmentLogs"`
+}
+
+// GetEnvironmentLogs returns EnvironmentLogsAfterResponse.EnvironmentLogs, and is useful for accessing the field via an interface.
+func (v *EnvironmentLogsAfterResponse) GetEnvironmentLogs() []*EnvironmentLogsEnvironmentLogsLog {
+	return v.EnvironmentLogs
+}
+
 // EnvironmentLogsEnvironmentLogsLog includes the requested fields of the GraphQL type Log.
 // The GraphQL type's documentation follows.
 //
@@ -128,6 +151,535 @@ func (v *EnvironmentLogsResponse) GetEnvironmentLogs() []*EnvironmentLogsEnviron
 	return v.EnvironmentLogs
 }
 
+// HttpLogsAfterResponse is returned by HttpLogsAfter on success.
+type HttpLogsAfterResponse struct {
+	// Fetch HTTP logs for a deployment
+	HttpLogs []*HttpLogsHttpLogsHttpLog `json:"httpLogs"`
+}
+
+// GetHttpLogs returns HttpLogsAfterResponse.HttpLogs, and is useful for accessing the field via an interface.
+func (v *HttpLogsAfterResponse) GetHttpLogs() []*HttpLogsHttpLogsHttpLog { return v.HttpLogs }
+
+// HttpLogsHttpLogsHttpLog includes the requested fields of the GraphQL type HttpLog.
+// The GraphQL type's documentation follows.
+//
+// The result of a http logs query.
+type HttpLogsHttpLogsHttpLog struct {
+	// The timestamp the log was created
+	Timestamp string `json:"timestamp"`
+	// The unique request ID
+	RequestId string `json:"requestId"`
+	// The deployment ID that was requested
+	DeploymentId string `json:"deploymentId"`
+	// The deployment instance ID that was requested
+	DeploymentInstanceId string `json:"deploymentInstanceId"`
+	// The edge region the client connected to
+	EdgeRegion string `json:"edgeRegion"`
+	// The request HTTP method
+	Method string `json:"method"`
+	// The requested host
+	Host string `json:"host"`
+	// The requested path
+	Path string `json:"path"`
+	// The http status of the log
+	HttpStatus int `json:"httpStatus"`
+	// The total duration the request took
+	TotalDuration int `json:"totalDuration"`
+	// How long the upstream request took to respond
+	UpstreamRqDuration int `json:"upstreamRqDuration"`
+	// The source IP of the request
+	SrcIp string `json:"srcIp"`
+	// The client user agent
+	ClientUa string `json:"clientUa"`
+	// The downstream HTTP protocol version
+	DownstreamProto string `json:"downstreamProto"`
+	// The upstream HTTP protocol version
+	UpstreamProto string `json:"upstreamProto"`
+	// The upstream address
+	UpstreamAddress string `json:"upstreamAddress"`
+	// Details about the upstream response
+	ResponseDetails string `json:"responseDetails"`
+	// Received bytes
+	RxBytes int `json:"rxBytes"`
+	// Outgoing bytes
+	TxBytes int `json:"txBytes"`
+}
+
+// GetTimestamp returns HttpLogsHttpLogsHttpLog.Timestamp, and is useful for accessing the field via an interface.
+func (v *HttpLogsHttpLogsHttpLog) GetTimestamp() string { return v.Timestamp }
+
+// GetRequestId returns HttpLogsHttpLogsHttpLog.RequestId, and is useful for accessing the field via an interface.
+func (v *HttpLogsHttpLogsHttpLog) GetRequestId() string { return v.RequestId }
+
+// GetDeploymentId returns HttpLogsHttpLogsHttpLog.DeploymentId, and is useful for accessing the field via an interface.
+func (v *HttpLogsHttpLogsHttpLog) GetDeploymentId() string { return v.DeploymentId }
+
+// GetDeploymentInstanceId returns HttpLogsHttpLogsHttpLog.DeploymentInstanceId, and is useful for accessing the field via an interface.
+func (v *HttpLogsHttpLogsHttpLog) GetDeploymentInstanceId() string { return v.DeploymentInstanceId }
+
+// GetEdgeRegion returns HttpLogsHttpLogsHttpLog.EdgeRegion, and is useful for accessing the field via an interface.
+func (v *HttpLogsHttpLogsHttpLog) GetEdgeRegion() string { return v.EdgeRegion }
+
+// GetMethod returns HttpLogsHttpLogsHttpLog.Method, and is useful for accessing the field via an interface.
+func (v *HttpLogsHttpLogsHttpLog) GetMethod() string { return v.Method }
+
+// GetHost returns HttpLogsHttpLogsHttpLog.Host, and is useful for accessing the field via an interface.
+func (v *HttpLogsHttpLogsHttpLog) GetHost() string { return v.Host }
+
+// GetPath returns HttpLogsHttpLogsHttpLog.Path, and is useful for accessing the field via an interface.
+func (v *HttpLogsHttpLogsHttpLog) GetPath() string { return v.Path }
+
+// GetHttpStatus returns HttpLogsHttpLogsHttpLog.HttpStatus, and is useful for accessing the field via an interface.
+func (v *HttpLogsHttpLogsHttpLog) GetHttpStatus() int { return v.HttpStatus }
+
+// GetTotalDuration returns HttpLogsHttpLogsHttpLog.TotalDuration, and is useful for accessing the field via an interface.
+func (v *HttpLogsHttpLogsHttpLog) GetTotalDuration() int { return v.TotalDuration }
+
+// GetUpstreamRqDuration returns HttpLogsHttpLogsHttpLog.UpstreamRqDuration, and is useful for accessing the field via an interface.
+func (v *HttpLogsHttpLogsHttpLog) GetUpstreamRqDuration() int { return v.UpstreamRqDuration }
+
+// GetSrcIp returns HttpLogsHttpLogsHttpLog.SrcIp, and is useful for accessing the field via an interface.
+func (v *HttpLogsHttpLogsHttpLog) GetSrcIp() string { return v.SrcIp }
+
+// GetClientUa returns HttpLogsHttpLogsHttpLog.ClientUa, and is useful for accessing the field via an interface.
+func (v *HttpLogsHttpLogsHttpLog) GetClientUa() string { return v.ClientUa }
+
+// GetDownstreamProto returns HttpLogsHttpLogsHttpLog.DownstreamProto, and is useful for accessing the field via an interface.
+func (v *HttpLogsHttpLogsHttpLog) GetDownstreamProto() string { return v.DownstreamProto }
+
+// GetUpstreamProto returns HttpLogsHttpLogsHttpLog.UpstreamProto, and is useful for accessing the field via an interface.
+func (v *HttpLogsHttpLogsHttpLog) GetUpstreamProto() string { return v.UpstreamProto }
+
+// GetUpstreamAddress returns HttpLogsHttpLogsHttpLog.UpstreamAddress, and is useful for accessing the field via an interface.
+func (v *HttpLogsHttpLogsHttpLog) GetUpstreamAddress() string { return v.UpstreamAddress }
+
+// GetResponseDetails returns HttpLogsHttpLogsHttpLog.ResponseDetails, and is useful for accessing the field via an interface.
+func (v *HttpLogsHttpLogsHttpLog) GetResponseDetails() string { return v.ResponseDetails }
+
+// GetRxBytes returns HttpLogsHttpLogsHttpLog.RxBytes, and is useful for accessing the field via an interface.
+func (v *HttpLogsHttpLogsHttpLog) GetRxBytes() int { return v.RxBytes }
+
+// GetTxBytes returns HttpLogsHttpLogsHttpLog.TxBytes, and is useful for accessing the field via an interface.
+func (v *HttpLogsHttpLogsHttpLog) GetTxBytes() int { return v.TxBytes }
+
+// HttpLogsResponse is returned by HttpLogs on success.
+type HttpLogsResponse struct {
+	// Fetch HTTP logs for a deployment
+	HttpLogs []*HttpLogsHttpLogsHttpLog `json:"httpLogs"`
+}
+
+// GetHttpLogs returns HttpLogsResponse.HttpLogs, and is useful for accessing the field via an interface.
+func (v *HttpLogsResponse) GetHttpLogs() []*HttpLogsHttpLogsHttpLog { return v.HttpLogs }
+
+// MeMeUser includes the requested fields of the GraphQL type User.
+type MeMeUser struct {
+	Id string `json:"id"`
+}
+
+// GetId returns MeMeUser.Id, and is useful for accessing the field via an interface.
+func (v *MeMeUser) GetId() string { return v.Id }
+
+// MeResponse is returned by Me on success.
+type MeResponse struct {
+	// Gets the authenticated user.
+	Me *MeMeUser `json:"me"`
+}
+
+// GetMe returns MeResponse.Me, and is useful for accessing the field via an interface.
+func (v *MeResponse) GetMe() *MeMeUser { return v.Me }
+
+// PluginLogsResponse is returned by PluginLogs on success.
+type PluginLogsResponse struct {
+	// Fetch logs for a plugin
+	PluginLogs []*EnvironmentLogsEnvironmentLogsLog `json:"pluginLogs"`
+}
+
+// GetPluginLogs returns PluginLogsResponse.PluginLogs, and is useful for accessing the field via an interface.
+func (v *PluginLogsResponse) GetPluginLogs() []*EnvironmentLogsEnvironmentLogsLog {
+	return v.PluginLogs
+}
+
+// ProjectProject includes the requested fields of the GraphQL type Project.
+type ProjectProject struct {
+	ProjectTree `json:"-"`
+}
+
+// GetId returns ProjectProject.Id, and is useful for accessing the field via an interface.
+func (v *ProjectProject) GetId() string { return v.ProjectTree.Id }
+
+// GetName returns ProjectProject.Name, and is useful for accessing the field via an interface.
+func (v *ProjectProject) GetName() string { return v.ProjectTree.Name }
+
+// GetEnvironments returns ProjectProject.Environments, and is useful for accessing the field via an interface.
+func (v *ProjectProject) GetEnvironments() *ProjectTreeEnvironmentsProjectEnvironmentsConnection {
+	return v.ProjectTree.Environments
+}
+
+// GetServices returns ProjectProject.Services, and is useful for accessing the field via an interface.
+func (v *ProjectProject) GetServices() *ProjectTreeServicesProjectServicesConnection {
+	return v.ProjectTree.Services
+}
+
+func (v *ProjectProject) UnmarshalJSON(b []byte) error {
+
+	if string(b) == "null" {
+		return nil
+	}
+
+	var firstPass struct {
+		*ProjectProject
+		graphql.NoUnmarshalJSON
+	}
+	firstPass.ProjectProject = v
+
+	err := json.Unmarshal(b, &firstPass)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(
+		b, &v.ProjectTree)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+type __premarshalProjectProject struct {
+	Id string `json:"id"`
+
+	Name string `json:"name"`
+
+	Environments *ProjectTreeEnvironmentsProjectEnvironmentsConnection `json:"environments"`
+
+	Services *ProjectTreeServicesProjectServicesConnection `json:"services"`
+}
+
+func (v *ProjectProject) MarshalJSON() ([]byte, error) {
+	premarshaled, err := v.__premarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(premarshaled)
+}
+
+func (v *ProjectProject) __premarshalJSON() (*__premarshalProjectProject, error) {
+	var retval __premarshalProjectProject
+
+	retval.Id = v.ProjectTree.Id
+	retval.Name = v.ProjectTree.Name
+	retval.Environments = v.ProjectTree.Environments
+	retval.Services = v.ProjectTree.Services
+	return &retval, nil
+}
+
+// ProjectResponse is returned by Project on success.
+type ProjectResponse struct {
+	// Get a project by ID
+	Project *ProjectProject `json:"project"`
+}
+
+// GetProject returns ProjectResponse.Project, and is useful for accessing the field via an interface.
+func (v *ProjectResponse) GetProject() *ProjectProject { return v.Project }
+
+// ProjectTokenProjectToken includes the requested fields of the GraphQL type ProjectToken.
+type ProjectTokenProjectToken struct {
+	ProjectId     string `json:"projectId"`
+	EnvironmentId string `json:"environmentId"`
+}
+
+// GetProjectId returns ProjectTokenProjectToken.ProjectId, and is useful for accessing the field via an interface.
+func (v *ProjectTokenProjectToken) GetProjectId() string { return v.ProjectId }
+
+// GetEnvironmentId returns ProjectTokenProjectToken.EnvironmentId, and is useful for accessing the field via an interface.
+func (v *ProjectTokenProjectToken) GetEnvironmentId() string { return v.EnvironmentId }
+
+// ProjectTokenResponse is returned by ProjectToken on success.
+type ProjectTokenResponse struct {
+	// Get a single project token by the value in the header
+	ProjectToken *ProjectTokenProjectToken `json:"projectToken"`
+}
+
+// GetProjectToken returns ProjectTokenResponse.ProjectToken, and is useful for accessing the field via an interface.
+func (v *ProjectTokenResponse) GetProjectToken() *ProjectTokenProjectToken { return v.ProjectToken }
+
+// ProjectTree includes the GraphQL fields of Project requested by the fragment ProjectTree.
+type ProjectTree struct {
+	Id           string                                                `json:"id"`
+	Name         string                                                `json:"name"`
+	Environments *ProjectTreeEnvironmentsProjectEnvironmentsConnection `json:"environments"`
+	Services     *ProjectTreeServicesProjectServicesConnection         `json:"services"`
+}
+
+// GetId returns ProjectTree.Id, and is useful for accessing the field via an interface.
+func (v *ProjectTree) GetId() string { return v.Id }
+
+// GetName returns ProjectTree.Name, and is useful for accessing the field via an interface.
+func (v *ProjectTree) GetName() string { return v.Name }
+
+// GetEnvironments returns ProjectTree.Environments, and is useful for accessing the field via an interface.
+func (v *ProjectTree) GetEnvironments() *ProjectTreeEnvironmentsProjectEnvironmentsConnection {
+	return v.Environments
+}
+
+// GetServices returns ProjectTree.Services, and is useful for accessing the field via an interface.
+func (v *ProjectTree) GetServices() *ProjectTreeServicesProjectServicesConnection { return v.Services }
+
+// ProjectTreeEnvironmentsProjectEnvironmentsConnection includes the requested fields of the GraphQL type ProjectEnvironmentsConnection.
+type ProjectTreeEnvironmentsProjectEnvironmentsConnection struct {
+	Edges []*ProjectTreeEnvironmentsProjectEnvironmentsConnectionEdgesProjectEnvironmentsConnectionEdge `json:"edges"`
+}
+
+// GetEdges returns ProjectTreeEnvironmentsProjectEnvironmentsConnection.Edges, and is useful for accessing the field via an interface.
+func (v *ProjectTreeEnvironmentsProjectEnvironmentsConnection) GetEdges() []*ProjectTreeEnvironmentsProjectEnvironmentsConnectionEdgesProjectEnvironmentsConnectionEdge {
+	return v.Edges
+}
+
+// ProjectTreeEnvironmentsProjectEnvironmentsConnectionEdgesProjectEnvironmentsConnectionEdge includes the requested fields of the GraphQL type ProjectEnvironmentsConnectionEdge.
+type ProjectTreeEnvironmentsProjectEnvironmentsConnectionEdgesProjectEnvironmentsConnectionEdge struct {
+	Node *ProjectTreeEnvironmentsProjectEnvironmentsConnectionEdgesProjectEnvironmentsConnectionEdgeNodeEnvironment `json:"node"`
+}
+
+// GetNode returns ProjectTreeEnvironmentsProjectEnvironmentsConnectionEdgesProjectEnvironmentsConnectionEdge.Node, and is useful for accessing the field via an interface.
+func (v *ProjectTreeEnvironmentsProjectEnvironmentsConnectionEdgesProjectEnvironmentsConnectionEdge) GetNode() *ProjectTreeEnvironmentsProjectEnvironmentsConnectionEdgesProjectEnvironmentsConnectionEdgeNodeEnvironment {
+	return v.Node
+}
+
+// ProjectTreeEnvironmentsProjectEnvironmentsConnectionEdgesProjectEnvironmentsConnectionEdgeNodeEnvironment includes the requested fields of the GraphQL type Environment.
+type ProjectTreeEnvironmentsProjectEnvironmentsConnectionEdgesProjectEnvironmentsConnectionEdgeNodeEnvironment struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// GetId returns ProjectTreeEnvironmentsProjectEnvironmentsConnectionEdgesProjectEnvironmentsConnectionEdgeNodeEnvironment.Id, and is useful for accessing the field via an interface.
+func (v *ProjectTreeEnvironmentsProjectEnvironmentsConnectionEdgesProjectEnvironmentsConnectionEdgeNodeEnvironment) GetId() string {
+	return v.Id
+}
+
+// GetName returns ProjectTreeEnvironmentsProjectEnvironmentsConnectionEdgesProjectEnvironmentsConnectionEdgeNodeEnvironment.Name, and is useful for accessing the field via an interface.
+func (v *ProjectTreeEnvironmentsProjectEnvironmentsConnectionEdgesProjectEnvironmentsConnectionEdgeNodeEnvironment) GetName() string {
+	return v.Name
+}
+
+// ProjectTreeServicesProjectServicesConnection includes the requested fields of the GraphQL type ProjectServicesConnection.
+type ProjectTreeServicesProjectServicesConnection struct {
+	Edges []*ProjectTreeServicesProjectServicesConnectionEdgesProjectServicesConnectionEdge `json:"edges"`
+}
+
+// GetEdges returns ProjectTreeServicesProjectServicesConnection.Edges, and is useful for accessing the field via an interface.
+func (v *ProjectTreeServicesProjectServicesConnection) GetEdges() []*ProjectTreeServicesProjectServicesConnectionEdgesProjectServicesConnectionEdge {
+	return v.Edges
+}
+
+// ProjectTreeServicesProjectServicesConnectionEdgesProjectServicesConnectionEdge includes the requested fields of the GraphQL type ProjectServicesConnectionEdge.
+type ProjectTreeServicesProjectServicesConnectionEdgesProjectServicesConnectionEdge struct {
+	Node *ProjectTreeServicesProjectServicesConnectionEdgesProjectServicesConnectionEdgeNodeService `json:"node"`
+}
+
+// GetNode returns ProjectTreeServicesProjectServicesConnectionEdgesProjectServicesConnectionEdge.Node, and is useful for accessing the field via an interface.
+func (v *ProjectTreeServicesProjectServicesConnectionEdgesProjectServicesConnectionEdge) GetNode() *ProjectTreeServicesProjectServicesConnectionEdgesProjectServicesConnectionEdgeNodeService {
+	return v.Node
+}
+
+// ProjectTreeServicesProjectServicesConnectionEdgesProjectServicesConnectionEdgeNodeService includes the requested fields of the GraphQL type Service.
+type ProjectTreeServicesProjectServicesConnectionEdgesProjectServicesConnectionEdgeNodeService struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// GetId returns ProjectTreeServicesProjectServicesConnectionEdgesProjectServicesConnectionEdgeNodeService.Id, and is useful for accessing the field via an interface.
+func (v *ProjectTreeServicesProjectServicesConnectionEdgesProjectServicesConnectionEdgeNodeService) GetId() string {
+	return v.Id
+}
+
+// GetName returns ProjectTreeServicesProjectServicesConnectionEdgesProjectServicesConnectionEdgeNodeService.Name, and is useful for accessing the field via an interface.
+func (v *ProjectTreeServicesProjectServicesConnectionEdgesProjectServicesConnectionEdgeNodeService) GetName() string {
+	return v.Name
+}
+
+// ProjectsProjectsQueryProjectsConnection includes the requested fields of the GraphQL type QueryProjectsConnection.
+type ProjectsProjectsQueryProjectsConnection struct {
+	Edges []*ProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdge `json:"edges"`
+}
+
+// GetEdges returns ProjectsProjectsQueryProjectsConnection.Edges, and is useful for accessing the field via an interface.
+func (v *ProjectsProjectsQueryProjectsConnection) GetEdges() []*ProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdge {
+	return v.Edges
+}
+
+// ProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdge includes the requested fields of the GraphQL type QueryProjectsConnectionEdge.
+type ProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdge struct {
+	Node *ProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdgeNodeProject `json:"node"`
+}
+
+// GetNode returns ProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdge.Node, and is useful for accessing the field via an interface.
+func (v *ProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdge) GetNode() *ProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdgeNodeProject {
+	return v.Node
+}
+
+// ProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdgeNodeProject includes the requested fields of the GraphQL type Project.
+type ProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdgeNodeProject struct {
+	ProjectTree `json:"-"`
+}
+
+// GetId returns ProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdgeNodeProject.Id, and is useful for accessing the field via an interface.
+func (v *ProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdgeNodeProject) GetId() string {
+	return v.ProjectTree.Id
+}
+
+// GetName returns ProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdgeNodeProject.Name, and is useful for accessing the field via an interface.
+func (v *ProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdgeNodeProject) GetName() string {
+	return v.ProjectTree.Name
+}
+
+// GetEnvironments returns ProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdgeNodeProject.Environments, and is useful for accessing the field via an interface.
+func (v *ProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdgeNodeProject) GetEnvironments() *ProjectTreeEnvironmentsProjectEnvironmentsConnection {
+	return v.ProjectTree.Environments
+}
+
+// GetServices returns ProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdgeNodeProject.Services, and is useful for accessing the field via an interface.
+func (v *ProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdgeNodeProject) GetServices() *ProjectTreeServicesProjectServicesConnection {
+	return v.ProjectTree.Services
+}
+
+func (v *ProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdgeNodeProject) UnmarshalJSON(b []byte) error {
+
+	if string(b) == "null" {
+		return nil
+	}
+
+	var firstPass struct {
+		*ProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdgeNodeProject
+		graphql.NoUnmarshalJSON
+	}
+	firstPass.ProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdgeNodeProject = v
+
+	err := json.Unmarshal(b, &firstPass)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(
+		b, &v.ProjectTree)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+type __premarshalProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdgeNodeProject struct {
+	Id string `json:"id"`
+
+	Name string `json:"name"`
+
+	Environments *ProjectTreeEnvironmentsProjectEnvironmentsConnection `json:"environments"`
+
+	Services *ProjectTreeServicesProjectServicesConnection `json:"services"`
+}
+
+func (v *ProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdgeNodeProject) MarshalJSON() ([]byte, error) {
+	premarshaled, err := v.__premarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(premarshaled)
+}
+
+func (v *ProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdgeNodeProject) __premarshalJSON() (*__premarshalProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdgeNodeProject, error) {
+	var retval __premarshalProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdgeNodeProject
+
+	retval.Id = v.ProjectTree.Id
+	retval.Name = v.ProjectTree.Name
+	retval.Environments = v.ProjectTree.Environments
+	retval.Services = v.ProjectTree.Services
+	return &retval, nil
+}
+
+// ProjectsResponse is returned by Projects on success.
+type ProjectsResponse struct {
+	// Gets all projects for a user or a team.
+	Projects *ProjectsProjectsQueryProjectsConnection `json:"projects"`
+}
+
+// GetProjects returns ProjectsResponse.Projects, and is useful for accessing the field via an interface.
+func (v *ProjectsResponse) GetProjects() *ProjectsProjectsQueryProjectsConnection { return v.Projects }
+
+// StreamEnvironmentLogsResponse is returned by StreamEnvironmentLogs on success.
+type StreamEnvironmentLogsResponse struct {
+	// Stream logs for a project environment
+	EnvironmentLogs []*EnvironmentLogsEnvironmentLogsLog `json:"environmentLogs"`
+}
+
+// GetEnvironmentLogs returns StreamEnvironmentLogsResponse.EnvironmentLogs, and is useful for accessing the field via an interface.
+func (v *StreamEnvironmentLogsResponse) GetEnvironmentLogs() []*EnvironmentLogsEnvironmentLogsLog {
+	return v.EnvironmentLogs
+}
+
+// TeamProjectsProjectsQueryProjectsConnection includes the requested fields of the GraphQL type QueryProjectsConnection.
+type TeamProjectsProjectsQueryProjectsConnection struct {
+	Edges []*TeamProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdge `json:"edges"`
+}
+
+// GetEdges returns TeamProjectsProjectsQueryProjectsConnection.Edges, and is useful for accessing the field via an interface.
+func (v *TeamProjectsProjectsQueryProjectsConnection) GetEdges() []*TeamProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdge {
+	return v.Edges
+}
+
+// TeamProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdge includes the requested fields of the GraphQL type QueryProjectsConnectionEdge.
+type TeamProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdge struct {
+	Node *TeamProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdgeNodeProject `json:"node"`
+}
+
+// GetNode returns TeamProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdge.Node, and is useful for accessing the field via an interface.
+func (v *TeamProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdge) GetNode() *TeamProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdgeNodeProject {
+	return v.Node
+}
+
+// TeamProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdgeNodeProject includes the requested fields of the GraphQL type Project.
+type TeamProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdgeNodeProject struct {
+	Id string `json:"id"`
+}
+
+// GetId returns TeamProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdgeNodeProject.Id, and is useful for accessing the field via an interface.
+func (v *TeamProjectsProjectsQueryProjectsConnectionEdgesQueryProjectsConnectionEdgeNodeProject) GetId() string {
+	return v.Id
+}
+
+// TeamProjectsResponse is returned by TeamProjects on success.
+type TeamProjectsResponse struct {
+	// Gets all projects for a user or a team.
+	Projects *TeamProjectsProjectsQueryProjectsConnection `json:"projects"`
+}
+
+// GetProjects returns TeamProjectsResponse.Projects, and is useful for accessing the field via an interface.
+func (v *TeamProjectsResponse) GetProjects() *TeamProjectsProjectsQueryProjectsConnection {
+	return v.Projects
+}
+
+// __BuildLogsInput is used internally by genqlient
+type __BuildLogsInput struct {
+	DeploymentId string    `json:"deploymentId"`
+	EndDate      time.Time `json:"endDate"`
+	Filter       string    `json:"filter"`
+	Limit        int       `json:"limit"`
+	StartDate    time.Time `json:"startDate"`
+}
+
+// GetDeploymentId returns __BuildLogsInput.DeploymentId, and is useful for accessing the field via an interface.
+func (v *__BuildLogsInput) GetDeploymentId() string { return v.DeploymentId }
+
+// GetEndDate returns __BuildLogsInput.EndDate, and is useful for accessing the field via an interface.
+func (v *__BuildLogsInput) GetEndDate() time.Time { return v.EndDate }
+
+// GetFilter returns __BuildLogsInput.Filter, and is useful for accessing the field via an interface.
+func (v *__BuildLogsInput) GetFilter() string { return v.Filter }
+
+// GetLimit returns __BuildLogsInput.Limit, and is useful for accessing the field via an interface.
+func (v *__BuildLogsInput) GetLimit() int { return v.Limit }
+
+// GetStartDate returns __BuildLogsInput.StartDate, and is useful for accessing the field via an interface.
+func (v *__BuildLogsInput) GetStartDate() time.Time { return v.StartDate }
+
 // __DeploymentInput is used internally by genqlient
 type __DeploymentInput struct {
 	Id string `json:"id"`
@@ -136,6 +688,30 @@ type __DeploymentInput struct {
 // GetId returns __DeploymentInput.Id, and is useful for accessing the field via an interface.
 func (v *__DeploymentInput) GetId() string { return v.Id }
 
+// __EnvironmentLogsAfterInput is used internally by genqlient
+type __EnvironmentLogsAfterInput struct {
+	AfterDate     string `json:"afterDate"`
+	AfterLimit    int    `json:"afterLimit"`
+	AnchorDate    string `json:"anchorDate"`
+	EnvironmentId string `json:"environmentId"`
+	Filter        string `json:"filter"`
+}
+
+// GetAfterDate returns __EnvironmentLogsAfterInput.AfterDate, and is useful for accessing the field via an interface.
+func (v *__EnvironmentLogsAfterInput) GetAfterDate() string { return v.AfterDate }
+
+// GetAfterLimit returns __EnvironmentLogsAfterInput.AfterLimit, and is useful for accessing the field via an interface.
+func (v *__EnvironmentLogsAfterInput) GetAfterLimit() int { return v.AfterLimit }
+
+// GetAnchorDate returns __EnvironmentLogsAfterInput.AnchorDate, and is useful for accessing the field via an interface.
+func (v *__EnvironmentLogsAfterInput) GetAnchorDate() string { return v.AnchorDate }
+
+// GetEnvironmentId returns __EnvironmentLogsAfterInput.EnvironmentId, and is useful for accessing the field via an interface.
+func (v *__EnvironmentLogsAfterInput) GetEnvironmentId() string { return v.EnvironmentId }
+
+// GetFilter returns __EnvironmentLogsAfterInput.Filter, and is useful for accessing the field via an interface.
+func (v *__EnvironmentLogsAfterInput) GetFilter() string { return v.Filter }
+
 // __EnvironmentLogsInput is used internally by genqlient
 type __EnvironmentLogsInput struct {
 	AfterLimit    int    `json:"afterLimit"`
@@ -164,54 +740,231 @@ func (v *__EnvironmentLogsInput) GetEnvironmentId() string { return v.Environmen
 // GetFilter returns __EnvironmentLogsInput.Filter, and is useful for accessing the field via an interface.
 func (v *__EnvironmentLogsInput) GetFilter() string { return v.Filter }
 
-// The query executed by Deployment.
-const Deployment_Operation = `
-query Deployment ($id: String!) {
-	deployment(id: $id) {
-		environmentId
-		projectId
-	}
+// __HttpLogsAfterInput is used internally by genqlient
+type __HttpLogsAfterInput struct {
+	AfterDate    string `json:"afterDate"`
+	AfterLimit   int    `json:"afterLimit"`
+	AnchorDate   string `json:"anchorDate"`
+	DeploymentId string `json:"deploymentId"`
+	Filter       string `json:"filter"`
 }
-`
 
-func Deployment(
-	ctx_ context.Context,
-	client_ graphql.Client,
-	id string,
-) (data_ *DeploymentResponse, err_ error) {
-	req_ := &graphql.Request{
-		OpName: "Deployment",
-		Query:  Deployment_Operation,
-		Variables: &__DeploymentInput{
-			Id: id,
-		},
-	}
+// GetAfterDate returns __HttpLogsAfterInput.AfterDate, and is useful for accessing the field via an interface.
+func (v *__HttpLogsAfterInput) GetAfterDate() string { return v.AfterDate }
 
-	data_ = &DeploymentResponse{}
-	resp_ := &graphql.Response{Data: data_}
+// GetAfterLimit returns __HttpLogsAfterInput.AfterLimit, and is useful for accessing the field via an interface.
+func (v *__HttpLogsAfterInput) GetAfterLimit() int { return v.AfterLimit }
 
-	err_ = client_.MakeRequest(
-		ctx_,
-		req_,
-		resp_,
-	)
+// GetAnchorDate returns __HttpLogsAfterInput.AnchorDate, and is useful for accessing the field via an interface.
+func (v *__HttpLogsAfterInput) GetAnchorDate() string { return v.AnchorDate }
 
-	return data_, err_
+// GetDeploymentId returns __HttpLogsAfterInput.DeploymentId, and is useful for accessing the field via an interface.
+func (v *__HttpLogsAfterInput) GetDeploymentId() string { return v.DeploymentId }
+
+// GetFilter returns __HttpLogsAfterInput.Filter, and is useful for accessing the field via an interface.
+func (v *__HttpLogsAfterInput) GetFilter() string { return v.Filter }
+
+// __HttpLogsInput is used internally by genqlient
+type __HttpLogsInput struct {
+	AfterLimit   int    `json:"afterLimit"`
+	AnchorDate   string `json:"anchorDate"`
+	BeforeDate   string `json:"beforeDate"`
+	BeforeLimit  int    `json:"beforeLimit"`
+	DeploymentId string `json:"deploymentId"`
+	Filter       string `json:"filter"`
 }
 
-// The query executed by EnvironmentLogs.
-const EnvironmentLogs_Operation = `
-query EnvironmentLogs ($afterLimit: Int, $anchorDate: String, $beforeDate: String, $beforeLimit: Int, $environmentId: String!, $filter: String) {
-	environmentLogs(afterLimit: $afterLimit, beforeDate: $beforeDate, anchorDate: $anchorDate, beforeLimit: $beforeLimit, environmentId: $environmentId, filter: $filter) {
-		attributes {
-			key
-			value
-		}
-		message
-		severity
-		tags {
-			deploymentId
-			deploymentInstanceId
+// GetAfterLimit returns __HttpLogsInput.AfterLimit, and is useful for accessing the field via an interface.
+func (v *__HttpLogsInput) GetAfterLimit() int { return v.AfterLimit }
+
+// GetAnchorDate returns __HttpLogsInput.AnchorDate, and is useful for accessing the field via an interface.
+func (v *__HttpLogsInput) GetAnchorDate() string { return v.AnchorDate }
+
+// GetBeforeDate returns __HttpLogsInput.BeforeDate, and is useful for accessing the field via an interface.
+func (v *__HttpLogsInput) GetBeforeDate() string { return v.BeforeDate }
+
+// GetBeforeLimit returns __HttpLogsInput.BeforeLimit, and is useful for accessing the field via an interface.
+func (v *__HttpLogsInput) GetBeforeLimit() int { return v.BeforeLimit }
+
+// GetDeploymentId returns __HttpLogsInput.DeploymentId, and is useful for accessing the field via an interface.
+func (v *__HttpLogsInput) GetDeploymentId() string { return v.DeploymentId }
+
+// GetFilter returns __HttpLogsInput.Filter, and is useful for accessing the field via an interface.
+func (v *__HttpLogsInput) GetFilter() string { return v.Filter }
+
+// __PluginLogsInput is used internally by genqlient
+type __PluginLogsInput struct {
+	EndDate       time.Time `json:"endDate"`
+	EnvironmentId string    `json:"environmentId"`
+	Filter        string    `json:"filter"`
+	Limit         int       `json:"limit"`
+	PluginId      string    `json:"pluginId"`
+	StartDate     time.Time `json:"startDate"`
+}
+
+// GetEndDate returns __PluginLogsInput.EndDate, and is useful for accessing the field via an interface.
+func (v *__PluginLogsInput) GetEndDate() time.Time { return v.EndDate }
+
+// GetEnvironmentId returns __PluginLogsInput.EnvironmentId, and is useful for accessing the field via an interface.
+func (v *__PluginLogsInput) GetEnvironmentId() string { return v.EnvironmentId }
+
+// GetFilter returns __PluginLogsInput.Filter, and is useful for accessing the field via an interface.
+func (v *__PluginLogsInput) GetFilter() string { return v.Filter }
+
+// GetLimit returns __PluginLogsInput.Limit, and is useful for accessing the field via an interface.
+func (v *__PluginLogsInput) GetLimit() int { return v.Limit }
+
+// GetPluginId returns __PluginLogsInput.PluginId, and is useful for accessing the field via an interface.
+func (v *__PluginLogsInput) GetPluginId() string { return v.PluginId }
+
+// GetStartDate returns __PluginLogsInput.StartDate, and is useful for accessing the field via an interface.
+func (v *__PluginLogsInput) GetStartDate() time.Time { return v.StartDate }
+
+// __ProjectInput is used internally by genqlient
+type __ProjectInput struct {
+	Id string `json:"id"`
+}
+
+// GetId returns __ProjectInput.Id, and is useful for accessing the field via an interface.
+func (v *__ProjectInput) GetId() string { return v.Id }
+
+// __StreamEnvironmentLogsInput is used internally by genqlient
+type __StreamEnvironmentLogsInput struct {
+	AfterDate     string `json:"afterDate,omitempty"`
+	AfterLimit    int    `json:"afterLimit,omitempty"`
+	AnchorDate    string `json:"anchorDate,omitempty"`
+	BeforeDate    string `json:"beforeDate,omitempty"`
+	BeforeLimit   int    `json:"beforeLimit,omitempty"`
+	EnvironmentId string `json:"environmentId"`
+	Filter        string `json:"filter,omitempty"`
+}
+
+// GetAfterDate returns __StreamEnvironmentLogsInput.AfterDate, and is useful for accessing the field via an interface.
+func (v *__StreamEnvironmentLogsInput) GetAfterDate() string { return v.AfterDate }
+
+// GetAfterLimit returns __StreamEnvironmentLogsInput.AfterLimit, and is useful for accessing the field via an interface.
+func (v *__StreamEnvironmentLogsInput) GetAfterLimit() int { return v.AfterLimit }
+
+// GetAnchorDate returns __StreamEnvironmentLogsInput.AnchorDate, and is useful for accessing the field via an interface.
+func (v *__StreamEnvironmentLogsInput) GetAnchorDate() string { return v.AnchorDate }
+
+// GetBeforeDate returns __StreamEnvironmentLogsInput.BeforeDate, and is useful for accessing the field via an interface.
+func (v *__StreamEnvironmentLogsInput) GetBeforeDate() string { return v.BeforeDate }
+
+// GetBeforeLimit returns __StreamEnvironmentLogsInput.BeforeLimit, and is useful for accessing the field via an interface.
+func (v *__StreamEnvironmentLogsInput) GetBeforeLimit() int { return v.BeforeLimit }
+
+// GetEnvironmentId returns __StreamEnvironmentLogsInput.EnvironmentId, and is useful for accessing the field via an interface.
+func (v *__StreamEnvironmentLogsInput) GetEnvironmentId() string { return v.EnvironmentId }
+
+// GetFilter returns __StreamEnvironmentLogsInput.Filter, and is useful for accessing the field via an interface.
+func (v *__StreamEnvironmentLogsInput) GetFilter() string { return v.Filter }
+
+// The query executed by BuildLogs.
+const BuildLogs_Operation = `
+query BuildLogs ($deploymentId: String!, $endDate: DateTime, $filter: String, $limit: Int, $startDate: DateTime) {
+	buildLogs(deploymentId: $deploymentId, endDate: $endDate, filter: $filter, limit: $limit, startDate: $startDate) {
+		attributes {
+			key
+			value
+		}
+		message
+		severity
+		tags {
+			deploymentId
+			deploymentInstanceId
+			environmentId
+			pluginId
+			projectId
+			serviceId
+			snapshotId
+		}
+		timestamp
+	}
+}
+`
+
+func BuildLogs(
+	ctx_ context.Context,
+	client_ graphql.Client,
+	deploymentId string,
+	endDate time.Time,
+	filter string,
+	limit int,
+	startDate time.Time,
+) (data_ *BuildLogsResponse, err_ error) {
+	req_ := &graphql.Request{
+		OpName: "BuildLogs",
+		Query:  BuildLogs_Operation,
+		Variables: &__BuildLogsInput{
+			DeploymentId: deploymentId,
+			EndDate:      endDate,
+			Filter:       filter,
+			Limit:        limit,
+			StartDate:    startDate,
+		},
+	}
+
+	data_ = &BuildLogsResponse{}
+	resp_ := &graphql.Response{Data: data_}
+
+	err_ = client_.MakeRequest(
+		ctx_,
+		req_,
+		resp_,
+	)
+
+	return data_, err_
+}
+
+// The query executed by Deployment.
+const Deployment_Operation = `
+query Deployment ($id: String!) {
+	deployment(id: $id) {
+		environmentId
+		projectId
+	}
+}
+`
+
+func Deployment(
+	ctx_ context.Context,
+	client_ graphql.Client,
+	id string,
+) (data_ *DeploymentResponse, err_ error) {
+	req_ := &graphql.Request{
+		OpName: "Deployment",
+		Query:  Deployment_Operation,
+		Variables: &__DeploymentInput{
+			Id: id,
+		},
+	}
+
+	data_ = &DeploymentResponse{}
+	resp_ := &graphql.Response{Data: data_}
+
+	err_ = client_.MakeRequest(
+		ctx_,
+		req_,
+		resp_,
+	)
+
+	return data_, err_
+}
+
+// The query executed by EnvironmentLogs.
+const EnvironmentLogs_Operation = `
+query EnvironmentLogs ($afterLimit: Int, $anchorDate: String, $beforeDate: String, $beforeLimit: Int, $environmentId: String!, $filter: String) {
+	environmentLogs(afterLimit: $afterLimit, beforeDate: $beforeDate, anchorDate: $anchorDate, beforeLimit: $beforeLimit, environmentId: $environmentId, filter: $filter) {
+		attributes {
+			key
+			value
+		}
+		message
+		severity
+		tags {
+			deploymentId
+			deploymentInstanceId
 			environmentId
 			pluginId
 			projectId
@@ -257,3 +1010,531 @@ func EnvironmentLogs(
 
 	return data_, err_
 }
+
+// The query executed by EnvironmentLogsAfter.
+const EnvironmentLogsAfter_Operation = `
+query EnvironmentLogsAfter ($afterDate: String, $afterLimit: Int, $anchorDate: String, $environmentId: String!, $filter: String) {
+	environmentLogs(afterDate: $afterDate, afterLimit: $afterLimit, anchorDate: $anchorDate, environmentId: $environmentId, filter: $filter) {
+		attributes {
+			key
+			value
+		}
+		message
+		severity
+		tags {
+			deploymentId
+			deploymentInstanceId
+			environmentId
+			pluginId
+			projectId
+			serviceId
+			snapshotId
+		}
+		timestamp
+	}
+}
+`
+
+// fetches the logs after the anchor date, used to catch up an existing log file on the logs that are newer than it
+func EnvironmentLogsAfter(
+	ctx_ context.Context,
+	client_ graphql.Client,
+	afterDate string,
+	afterLimit int,
+	anchorDate string,
+	environmentId string,
+	filter string,
+) (data_ *EnvironmentLogsAfterResponse, err_ error) {
+	req_ := &graphql.Request{
+		OpName: "EnvironmentLogsAfter",
+		Query:  EnvironmentLogsAfter_Operation,
+		Variables: &__EnvironmentLogsAfterInput{
+			AfterDate:     afterDate,
+			AfterLimit:    afterLimit,
+			AnchorDate:    anchorDate,
+			EnvironmentId: environmentId,
+			Filter:        filter,
+		},
+	}
+
+	data_ = &EnvironmentLogsAfterResponse{}
+	resp_ := &graphql.Response{Data: data_}
+
+	err_ = client_.MakeRequest(
+		ctx_,
+		req_,
+		resp_,
+	)
+
+	return data_, err_
+}
+
+// The query executed by HttpLogs.
+const HttpLogs_Operation = `
+query HttpLogs ($afterLimit: Int, $anchorDate: String, $beforeDate: String, $beforeLimit: Int, $deploymentId: String!, $filter: String) {
+	httpLogs(afterLimit: $afterLimit, beforeDate: $beforeDate, anchorDate: $anchorDate, beforeLimit: $beforeLimit, deploymentId: $deploymentId, filter: $filter) {
+		timestamp
+		requestId
+		deploymentId
+		deploymentInstanceId
+		edgeRegion
+		method
+		host
+		path
+		httpStatus
+		totalDuration
+		upstreamRqDuration
+		srcIp
+		clientUa
+		downstreamProto
+		upstreamProto
+		upstreamAddress
+		responseDetails
+		rxBytes
+		txBytes
+	}
+}
+`
+
+func HttpLogs(
+	ctx_ context.Context,
+	client_ graphql.Client,
+	afterLimit int,
+	anchorDate string,
+	beforeDate string,
+	beforeLimit int,
+	deploymentId string,
+	filter string,
+) (data_ *HttpLogsResponse, err_ error) {
+	req_ := &graphql.Request{
+		OpName: "HttpLogs",
+		Query:  HttpLogs_Operation,
+		Variables: &__HttpLogsInput{
+			AfterLimit:   afterLimit,
+			AnchorDate:   anchorDate,
+			BeforeDate:   beforeDate,
+			BeforeLimit:  beforeLimit,
+			DeploymentId: deploymentId,
+			Filter:       filter,
+		},
+	}
+
+	data_ = &HttpLogsResponse{}
+	resp_ := &graphql.Response{Data: data_}
+
+	err_ = client_.MakeRequest(
+		ctx_,
+		req_,
+		resp_,
+	)
+
+	return data_, err_
+}
+
+// The query executed by HttpLogsAfter.
+const HttpLogsAfter_Operation = `
+query HttpLogsAfter ($afterDate: String, $afterLimit: Int, $anchorDate: String, $deploymentId: String!, $filter: String) {
+	httpLogs(afterDate: $afterDate, afterLimit: $afterLimit, anchorDate: $anchorDate, deploymentId: $deploymentId, filter: $filter) {
+		timestamp
+		requestId
+		deploymentId
+		deploymentInstanceId
+		edgeRegion
+		method
+		host
+		path
+		httpStatus
+		totalDuration
+		upstreamRqDuration
+		srcIp
+		clientUa
+		downstreamProto
+		upstreamProto
+		upstreamAddress
+		responseDetails
+		rxBytes
+		txBytes
+	}
+}
+`
+
+func HttpLogsAfter(
+	ctx_ context.Context,
+	client_ graphql.Client,
+	afterDate string,
+	afterLimit int,
+	anchorDate string,
+	deploymentId string,
+	filter string,
+) (data_ *HttpLogsAfterResponse, err_ error) {
+	req_ := &graphql.Request{
+		OpName: "HttpLogsAfter",
+		Query:  HttpLogsAfter_Operation,
+		Variables: &__HttpLogsAfterInput{
+			AfterDate:    afterDate,
+			AfterLimit:   afterLimit,
+			AnchorDate:   anchorDate,
+			DeploymentId: deploymentId,
+			Filter:       filter,
+		},
+	}
+
+	data_ = &HttpLogsAfterResponse{}
+	resp_ := &graphql.Response{Data: data_}
+
+	err_ = client_.MakeRequest(
+		ctx_,
+		req_,
+		resp_,
+	)
+
+	return data_, err_
+}
+
+// The query executed by Me.
+const Me_Operation = `
+query Me {
+	me {
+		id
+	}
+}
+`
+
+// only works with an account token, team tokens don't belong to a user
+func Me(
+	ctx_ context.Context,
+	client_ graphql.Client,
+) (data_ *MeResponse, err_ error) {
+	req_ := &graphql.Request{
+		OpName: "Me",
+		Query:  Me_Operation,
+	}
+
+	data_ = &MeResponse{}
+	resp_ := &graphql.Response{Data: data_}
+
+	err_ = client_.MakeRequest(
+		ctx_,
+		req_,
+		resp_,
+	)
+
+	return data_, err_
+}
+
+// The query executed by PluginLogs.
+const PluginLogs_Operation = `
+query PluginLogs ($endDate: DateTime, $environmentId: String!, $filter: String, $limit: Int, $pluginId: String!, $startDate: DateTime) {
+	pluginLogs(endDate: $endDate, environmentId: $environmentId, filter: $filter, limit: $limit, pluginId: $pluginId, startDate: $startDate) {
+		attributes {
+			key
+			value
+		}
+		message
+		severity
+		tags {
+			deploymentId
+			deploymentInstanceId
+			environmentId
+			pluginId
+			projectId
+			serviceId
+			snapshotId
+		}
+		timestamp
+	}
+}
+`
+
+func PluginLogs(
+	ctx_ context.Context,
+	client_ graphql.Client,
+	endDate time.Time,
+	environmentId string,
+	filter string,
+	limit int,
+	pluginId string,
+	startDate time.Time,
+) (data_ *PluginLogsResponse, err_ error) {
+	req_ := &graphql.Request{
+		OpName: "PluginLogs",
+		Query:  PluginLogs_Operation,
+		Variables: &__PluginLogsInput{
+			EndDate:       endDate,
+			EnvironmentId: environmentId,
+			Filter:        filter,
+			Limit:         limit,
+			PluginId:      pluginId,
+			StartDate:     startDate,
+		},
+	}
+
+	data_ = &PluginLogsResponse{}
+	resp_ := &graphql.Response{Data: data_}
+
+	err_ = client_.MakeRequest(
+		ctx_,
+		req_,
+		resp_,
+	)
+
+	return data_, err_
+}
+
+// The query executed by Project.
+const Project_Operation = `
+query Project ($id: String!) {
+	project(id: $id) {
+		... ProjectTree
+	}
+}
+fragment ProjectTree on Project {
+	id
+	name
+	environments {
+		edges {
+			node {
+				id
+				name
+			}
+		}
+	}
+	services {
+		edges {
+			node {
+				id
+				name
+			}
+		}
+	}
+}
+`
+
+func Project(
+	ctx_ context.Context,
+	client_ graphql.Client,
+	id string,
+) (data_ *ProjectResponse, err_ error) {
+	req_ := &graphql.Request{
+		OpName: "Project",
+		Query:  Project_Operation,
+		Variables: &__ProjectInput{
+			Id: id,
+		},
+	}
+
+	data_ = &ProjectResponse{}
+	resp_ := &graphql.Response{Data: data_}
+
+	err_ = client_.MakeRequest(
+		ctx_,
+		req_,
+		resp_,
+	)
+
+	return data_, err_
+}
+
+// The query executed by ProjectToken.
+const ProjectToken_Operation = `
+query ProjectToken {
+	projectToken {
+		projectId
+		environmentId
+	}
+}
+`
+
+// only works with a project token, returns the project and environment the token belongs to
+func ProjectToken(
+	ctx_ context.Context,
+	client_ graphql.Client,
+) (data_ *ProjectTokenResponse, err_ error) {
+	req_ := &graphql.Request{
+		OpName: "ProjectToken",
+		Query:  ProjectToken_Operation,
+	}
+
+	data_ = &ProjectTokenResponse{}
+	resp_ := &graphql.Response{Data: data_}
+
+	err_ = client_.MakeRequest(
+		ctx_,
+		req_,
+		resp_,
+	)
+
+	return data_, err_
+}
+
+// The query executed by Projects.
+const Projects_Operation = `
+query Projects {
+	projects {
+		edges {
+			node {
+				... ProjectTree
+			}
+		}
+	}
+}
+fragment ProjectTree on Project {
+	id
+	name
+	environments {
+		edges {
+			node {
+				id
+				name
+			}
+		}
+	}
+	services {
+		edges {
+			node {
+				id
+				name
+			}
+		}
+	}
+}
+`
+
+// every project the token can see, used to look up projects, environments and services by name
+func Projects(
+	ctx_ context.Context,
+	client_ graphql.Client,
+) (data_ *ProjectsResponse, err_ error) {
+	req_ := &graphql.Request{
+		OpName: "Projects",
+		Query:  Projects_Operation,
+	}
+
+	data_ = &ProjectsResponse{}
+	resp_ := &graphql.Response{Data: data_}
+
+	err_ = client_.MakeRequest(
+		ctx_,
+		req_,
+		resp_,
+	)
+
+	return data_, err_
+}
+
+// The subscription executed by StreamEnvironmentLogs.
+const StreamEnvironmentLogs_Operation = `
+subscription StreamEnvironmentLogs ($afterDate: String, $afterLimit: Int, $anchorDate: String, $beforeDate: String, $beforeLimit: Int, $environmentId: String!, $filter: String) {
+	environmentLogs(afterDate: $afterDate, afterLimit: $afterLimit, anchorDate: $anchorDate, beforeDate: $beforeDate, beforeLimit: $beforeLimit, environmentId: $environmentId, filter: $filter) {
+		attributes {
+			key
+			value
+		}
+		message
+		severity
+		tags {
+			deploymentId
+			deploymentInstanceId
+			environmentId
+			pluginId
+			projectId
+			serviceId
+			snapshotId
+		}
+		timestamp
+	}
+}
+`
+
+// unset dates and limits are left out so the stream starts at the newest log
+// To unsubscribe, use [graphql.WebSocketClient.Unsubscribe]
+func StreamEnvironmentLogs(
+	ctx_ context.Context,
+	client_ graphql.WebSocketClient,
+	afterDate string,
+	afterLimit int,
+	anchorDate string,
+	beforeDate string,
+	beforeLimit int,
+	environmentId string,
+	filter string,
+) (dataChan_ chan StreamEnvironmentLogsWsResponse, subscriptionID_ string, err_ error) {
+	req_ := &graphql.Request{
+		OpName: "StreamEnvironmentLogs",
+		Query:  StreamEnvironmentLogs_Operation,
+		Variables: &__StreamEnvironmentLogsInput{
+			AfterDate:     afterDate,
+			AfterLimit:    afterLimit,
+			AnchorDate:    anchorDate,
+			BeforeDate:    beforeDate,
+			BeforeLimit:   beforeLimit,
+			EnvironmentId: environmentId,
+			Filter:        filter,
+		},
+	}
+
+	dataChan_ = make(chan StreamEnvironmentLogsWsResponse)
+	subscriptionID_, err_ = client_.Subscribe(req_, dataChan_, StreamEnvironmentLogsForwardData)
+
+	return dataChan_, subscriptionID_, err_
+}
+
+type StreamEnvironmentLogsWsResponse graphql.BaseResponse[*StreamEnvironmentLogsResponse]
+
+func StreamEnvironmentLogsForwardData(interfaceChan interface{}, jsonRawMsg json.RawMessage) error {
+	var gqlResp graphql.Response
+	var wsResp StreamEnvironmentLogsWsResponse
+	err := json.Unmarshal(jsonRawMsg, &gqlResp)
+	if err != nil {
+		return err
+	}
+	if len(gqlResp.Errors) == 0 {
+		err = json.Unmarshal(jsonRawMsg, &wsResp)
+		if err != nil {
+			return err
+		}
+	} else {
+		wsResp.Errors = gqlResp.Errors
+	}
+	dataChan_, ok := interfaceChan.(chan StreamEnvironmentLogsWsResponse)
+	if !ok {
+		return errors.New("failed to cast interface into 'chan StreamEnvironmentLogsWsResponse'")
+	}
+	dataChan_ <- wsResp
+	return nil
+}
+
+// The query executed by TeamProjects.
+const TeamProjects_Operation = `
+query TeamProjects {
+	projects(first: 1) {
+		edges {
+			node {
+				id
+			}
+		}
+	}
+}
+`
+
+// works with an account or a team token, a token that can't list projects isn't a team token either
+func TeamProjects(
+	ctx_ context.Context,
+	client_ graphql.Client,
+) (data_ *TeamProjectsResponse, err_ error) {
+	req_ := &graphql.Request{
+		OpName: "TeamProjects",
+		Query:  TeamProjects_Operation,
+	}
+
+	data_ = &TeamProjectsResponse{}
+	resp_ := &graphql.Response{Data: data_}
+
+	err_ = client_.MakeRequest(
+		ctx_,
+		req_,
+		resp_,
+	)
+
+	return data_, err_
+}
@@ -12,6 +12,42 @@ var (
 )
 
 func GetAllDeploymentLogsBlocking(ctx context.Context, railwayClient *RailwayClient, logs chan<- LogLinesResponse, options GetLogsOptions) error {
+	environmentId, filter, err := resolveEnvironmentLogsFilter(ctx, railwayClient, options)
+	if err != nil {
+		return err
+	}
+
+	return paginateBackwards(ctx, options, environmentLogsQuery(railwayClient, options, environmentId, filter), sendEnvironmentLogs(logs, options.Shard))
+}
+
+func GetAllDeploymentLogsAsync(ctx context.Context, railwayClient *RailwayClient, logs chan<- LogLinesResponse, options GetLogsOptions) {
+	go func() {
+		if err := GetAllDeploymentLogsBlocking(ctx, railwayClient, logs, options); err != nil {
+			options.ErrorChannel <- err
+			return
+		}
+
+		options.DoneChannel <- true
+	}()
+}
+
+// fetchLogsPageFunc fetches up to limit logs that are older than or equal to endDate, ordered oldest first
+type fetchLogsPageFunc func(ctx context.Context, endDate time.Time, limit int) ([]*EnvironmentLogsEnvironmentLogsLog, error)
+
+// getAllWindowedLogsBlocking walks backwards through queries that only take a startDate/endDate window and a limit,
+// these queries can't page forwards so a full page that shares one timestamp stops the download with ErrBurstTooLarge
+// instead of skipping past the logs of it that didn't fit
+func getAllWindowedLogsBlocking(ctx context.Context, logs chan<- LogLinesResponse, options GetLogsOptions, fetchPage fetchLogsPageFunc) error {
+	return paginateBackwards(ctx, options, pageQuery[*EnvironmentLogsEnvironmentLogsLog]{
+		before:      fetchPage,
+		timestampOf: func(log *EnvironmentLogsEnvironmentLogsLog) string { return log.Timestamp },
+		keyOf:       environmentLogKey,
+	}, sendEnvironmentLogs(logs, options.Shard))
+}
+
+// resolveEnvironmentLogsFilter returns the environment to query environmentLogs in and the filter that scopes it to the service or deployment,
+// the environment of a deployment is looked up when it isn't provided
+func resolveEnvironmentLogsFilter(ctx context.Context, railwayClient *RailwayClient, options GetLogsOptions) (environmentId string, filter string, err error) {
 	var attribute string
 	var value string
 
@@ -21,126 +57,83 @@ func GetAllDeploymentLogsBlocking(ctx context.Context, railwayClient *RailwayCli
 	} else if options.DeploymentId != "" {
 		attribute = "deployment"
 		value = options.DeploymentId
-	} else {
-		return ErrFilterIDRequired
+	} else if options.EnvironmentId == "" {
+		return "", "", ErrFilterIDRequired
 	}
 
-	timestamp := time.Now().UTC().Format(time.RFC3339Nano)
-
-	if !options.ResumeFromTimestamp.IsZero() {
-		timestamp = options.ResumeFromTimestamp.UTC().Format(time.RFC3339Nano)
-	}
-
-	environmentId := options.EnvironmentId
+	environmentId = options.EnvironmentId
 
 	if environmentId == "" {
 		deployment, err := Deployment(ctx, railwayClient, options.DeploymentId)
 		if err != nil {
-			return fmt.Errorf("%w: %w", ErrFailedToGetDeployment, err)
+			return "", "", fmt.Errorf("%w: %w", ErrFailedToGetDeployment, err)
 		}
 
 		environmentId = deployment.Deployment.EnvironmentId
 	}
 
-	filter := buildFilter(attribute, value, options.Filter)
-
-	logsToFetch := MAX_LOG_FETCH
-
-	loopCount := 0
-	errorCount := 0
+	return environmentId, buildFilter(attribute, value, options.Filter), nil
+}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return nil
-		default:
-		}
+// buildFilter scopes the filter to the given attribute, an empty attribute leaves the filter scoped to the whole environment
+func buildFilter(attribute string, value string, filter string) string {
+	if attribute == "" {
+		return filter
+	}
 
-		if options.ResumeFromTimestamp.IsZero() {
-			switch loopCount {
-			case 0:
-				logsToFetch = logsToFetch - 1
-			case 1:
-				logsToFetch = logsToFetch + 1
-			}
-		}
+	filterString := fmt.Sprintf("@%s:%s", attribute, value)
 
-		logsResponse, err := EnvironmentLogs(ctx, railwayClient,
-			0,         // after limit
-			timestamp, // anchor date
-			time.Unix(0, 0).UTC().Format(time.RFC3339Nano), // before limit (Unix epoch)
-			logsToFetch,
-			environmentId, // environment id
-			filter,        // filter
-		)
-		if err != nil {
-			errorCount++
+	if filter != "" {
+		filterString = fmt.Sprintf("%s %s", filterString, filter)
+	}
 
-			// dead simple retry logic with static backoff
-			if errorCount < MAX_RETRY_COUNT {
-				time.Sleep(time.Second)
+	return filterString
+}
 
-				continue
+// environmentLogsQuery pages through the environment logs, scoped by the filter
+func environmentLogsQuery(railwayClient *RailwayClient, options GetLogsOptions, environmentId string, filter string) pageQuery[*EnvironmentLogsEnvironmentLogsLog] {
+	return pageQuery[*EnvironmentLogsEnvironmentLogsLog]{
+		before: func(ctx context.Context, anchor time.Time, limit int) ([]*EnvironmentLogsEnvironmentLogsLog, error) {
+			logsResponse, err := EnvironmentLogs(ctx, railwayClient,
+				0,                               // after limit
+				anchor.Format(time.RFC3339Nano), // anchor date
+				options.startDate().Format(time.RFC3339Nano), // before date (Unix epoch unless --since is set)
+				limit,
+				environmentId, // environment id
+				filter,        // filter
+			)
+			if err != nil {
+				return nil, err
 			}
 
-			return fmt.Errorf("%w: %w", ErrFailedToGetLogs, err)
-		}
-
-		// reset the error count on a successful fetch
-		errorCount = 0
-
-		if len(logsResponse.EnvironmentLogs) == 0 {
-			return ErrNoLogsFound
-		}
-
-		// we've reached the end of the logs
-		if logsResponse.EnvironmentLogs[0].Timestamp == timestamp {
-			break
-		}
-
-		// parse the first log timestamp
-		firstLogTimestamp, err := time.Parse(time.RFC3339Nano, logsResponse.EnvironmentLogs[0].Timestamp)
-		if err != nil {
-			return fmt.Errorf("%w: %w", ErrFailedToParseTimestamp, err)
-		}
-
-		if logsResponse.EnvironmentLogs[len(logsResponse.EnvironmentLogs)-1].Timestamp == timestamp {
-			logs <- LogLinesResponse{
-				Logs:               logsResponse.EnvironmentLogs[:len(logsResponse.EnvironmentLogs)-1],
-				OldestLogTimestamp: firstLogTimestamp,
-			}
-		} else {
-			logs <- LogLinesResponse{
-				Logs:               logsResponse.EnvironmentLogs,
-				OldestLogTimestamp: firstLogTimestamp,
+			return logsResponse.EnvironmentLogs, nil
+		},
+		after: func(ctx context.Context, anchor time.Time, until time.Time, limit int) ([]*EnvironmentLogsEnvironmentLogsLog, error) {
+			logsResponse, err := EnvironmentLogsAfter(ctx, railwayClient,
+				until.Format(time.RFC3339Nano),  // after date
+				limit,                           // after limit
+				anchor.Format(time.RFC3339Nano), // anchor date
+				environmentId,                   // environment id
+				filter,                          // filter
+			)
+			if err != nil {
+				return nil, err
 			}
-		}
-
-		timestamp = logsResponse.EnvironmentLogs[0].Timestamp
 
-		loopCount++
+			return logsResponse.EnvironmentLogs, nil
+		},
+		timestampOf: func(log *EnvironmentLogsEnvironmentLogsLog) string { return log.Timestamp },
+		keyOf:       environmentLogKey,
 	}
-
-	return nil
 }
 
-func GetAllDeploymentLogsAsync(ctx context.Context, railwayClient *RailwayClient, logs chan<- LogLinesResponse, options GetLogsOptions) {
-	go func() {
-		if err := GetAllDeploymentLogsBlocking(ctx, railwayClient, logs, options); err != nil {
-			options.ErrorChannel <- err
-			return
+// sendEnvironmentLogs sends every page of logs to the logs channel, along with the window of a sharded download they came from
+func sendEnvironmentLogs(logs chan<- LogLinesResponse, shard int) func(page []*EnvironmentLogsEnvironmentLogsLog, oldestLogTimestamp time.Time) {
+	return func(page []*EnvironmentLogsEnvironmentLogsLog, oldestLogTimestamp time.Time) {
+		logs <- LogLinesResponse{
+			Logs:               page,
+			OldestLogTimestamp: oldestLogTimestamp,
+			Shard:              shard,
 		}
-
-		options.DoneChannel <- true
-	}()
-}
-
-func buildFilter(attribute string, value string, filter string) string {
-	filterString := fmt.Sprintf("@%s:%s", attribute, value)
-
-	if filter != "" {
-		filterString = fmt.Sprintf("%s %s", filterString, filter)
 	}
-
-	return filterString
 }
@@ -0,0 +1,73 @@
+package railway
+
+import (
+	"context"
+	"time"
+)
+
+func GetAllHttpLogsBlocking(ctx context.Context, railwayClient *RailwayClient, logs chan<- LogLinesResponse, options GetLogsOptions) error {
+	if options.DeploymentId == "" {
+		return ErrDeploymentIdRequired
+	}
+
+	return paginateBackwards(ctx, options, httpLogsQuery(railwayClient, options), sendHttpLogs(logs, options.Shard))
+}
+
+func GetAllHttpLogsAsync(ctx context.Context, railwayClient *RailwayClient, logs chan<- LogLinesResponse, options GetLogsOptions) {
+	go func() {
+		if err := GetAllHttpLogsBlocking(ctx, railwayClient, logs, options); err != nil {
+			options.ErrorChannel <- err
+			return
+		}
+
+		options.DoneChannel <- true
+	}()
+}
+
+// httpLogsQuery pages through the http logs of the deployment
+func httpLogsQuery(railwayClient *RailwayClient, options GetLogsOptions) pageQuery[*HttpLogsHttpLogsHttpLog] {
+	return pageQuery[*HttpLogsHttpLogsHttpLog]{
+		before: func(ctx context.Context, anchor time.Time, limit int) ([]*HttpLogsHttpLogsHttpLog, error) {
+			logsResponse, err := HttpLogs(ctx, railwayClient,
+				0,                               // after limit
+				anchor.Format(time.RFC3339Nano), // anchor date
+				options.startDate().Format(time.RFC3339Nano), // before date (Unix epoch unless --since is set)
+				limit,
+				options.DeploymentId, // deployment id
+				options.Filter,       // filter
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			return logsResponse.HttpLogs, nil
+		},
+		after: func(ctx context.Context, anchor time.Time, until time.Time, limit int) ([]*HttpLogsHttpLogsHttpLog, error) {
+			logsResponse, err := HttpLogsAfter(ctx, railwayClient,
+				until.Format(time.RFC3339Nano),  // after date
+				limit,                           // after limit
+				anchor.Format(time.RFC3339Nano), // anchor date
+				options.DeploymentId,            // deployment id
+				options.Filter,                  // filter
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			return logsResponse.HttpLogs, nil
+		},
+		timestampOf: func(log *HttpLogsHttpLogsHttpLog) string { return log.Timestamp },
+		keyOf:       httpLogKey,
+	}
+}
+
+// sendHttpLogs sends every page of logs to the logs channel, along with the window of a sharded download they came from
+func sendHttpLogs(logs chan<- LogLinesResponse, shard int) func(page []*HttpLogsHttpLogsHttpLog, oldestLogTimestamp time.Time) {
+	return func(page []*HttpLogsHttpLogsHttpLog, oldestLogTimestamp time.Time) {
+		logs <- LogLinesResponse{
+			HttpLogs:           page,
+			OldestLogTimestamp: oldestLogTimestamp,
+			Shard:              shard,
+		}
+	}
+}
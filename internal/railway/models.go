@@ -4,18 +4,85 @@ import "time"
 
 type LogLinesResponse struct {
 	Logs               []*EnvironmentLogsEnvironmentLogsLog
+	HttpLogs           []*HttpLogsHttpLogsHttpLog
 	OldestLogTimestamp time.Time
+
+	// Shard is the window of a sharded download the logs came from
+	Shard int
 }
 
 type GetLogsOptions struct {
 	ResumeFromTimestamp time.Time
 
+	// ResumeSavedAtTimestamp is the number of logs at ResumeFromTimestamp that are already saved, only used when resuming
+	ResumeSavedAtTimestamp int
+
+	// CatchUpFromTimestamp is the newest log that was already downloaded, only used when catching up on newer logs
+	CatchUpFromTimestamp time.Time
+
+	// CatchUpSavedAtTimestamp is the number of logs at CatchUpFromTimestamp that are already saved, the others at that timestamp
+	// are downloaded too, used when continuing an interrupted catch up
+	CatchUpSavedAtTimestamp int
+
+	// Since and Until limit the logs to a time range, the zero time leaves that end of the range open
+	Since time.Time
+	Until time.Time
+
+	// RetryPolicy decides how failed requests are retried, the zero value uses DefaultRetryPolicy
+	RetryPolicy RetryPolicy
+
+	// Shards is the number of windows the time range is split into when the logs are downloaded in parallel
+	Shards int
+
+	// Shard is the window these options download, it is passed back with every page of logs
+	Shard int
+
+	// Windows are the windows of an interrupted sharded download to continue, nil splits the time range into new ones
+	Windows []TimeWindow
+
+	// OnWindows is called with the windows of a sharded download before they are downloaded,
+	// and OnShardDone every time one of them is completely downloaded, from the goroutine of that window
+	OnWindows   func(windows []TimeWindow)
+	OnShardDone func(shard int)
+
 	DeploymentId  string
 	EnvironmentId string
 	ServiceId     string
+	PluginId      string
 
 	Filter string
 
 	ErrorChannel chan error // Not used in blocking mode
 	DoneChannel  chan bool  // Not used in blocking mode
 }
+
+// ProjectTarget is a single environment and service pair of a project
+type ProjectTarget struct {
+	EnvironmentId   string
+	EnvironmentName string
+
+	ServiceId   string
+	ServiceName string
+}
+
+// startDate returns the oldest point in time to fetch logs from
+func (o GetLogsOptions) startDate() time.Time {
+	if !o.Since.IsZero() {
+		return o.Since.UTC()
+	}
+
+	return time.Unix(0, 0).UTC()
+}
+
+// endDate returns the newest point in time to fetch logs from, a resumed download continues from the oldest saved log
+func (o GetLogsOptions) endDate() time.Time {
+	if !o.ResumeFromTimestamp.IsZero() {
+		return o.ResumeFromTimestamp.UTC()
+	}
+
+	if !o.Until.IsZero() {
+		return o.Until.UTC()
+	}
+
+	return time.Now().UTC()
+}
@@ -0,0 +1,419 @@
+package railway
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// pageQuery fetches one kind of log on either side of an anchor, pages are ordered oldest first
+type pageQuery[T any] struct {
+	// before fetches up to limit of the newest logs at or before the anchor that aren't older than the start of the time range
+	before func(ctx context.Context, anchor time.Time, limit int) ([]T, error)
+
+	// after fetches up to limit of the oldest logs after the anchor that aren't newer than until,
+	// nil when the query can only page backwards
+	after func(ctx context.Context, anchor time.Time, until time.Time, limit int) ([]T, error)
+
+	timestampOf func(T) string
+	keyOf       func(T) string
+}
+
+// logMultiset counts logs by their key, the logs that share a timestamp can only be told apart by their content
+// and a service can log the same line more than once at the same time
+type logMultiset map[string]int
+
+func (m logMultiset) add(key string) {
+	m[key]++
+}
+
+// take removes one log with the key, reporting if there was one to remove
+func (m logMultiset) take(key string) bool {
+	if m[key] == 0 {
+		return false
+	}
+
+	m[key]--
+
+	return true
+}
+
+// paginateBackwards walks from the end of the time range to its start, newest page first.
+//
+// Every page is anchored at the oldest log of the previous one, so the logs that share that timestamp come back again
+// and the ones that were already sent are dropped by their content. When a whole page shares one timestamp the anchor
+// can't move past it, so the oldest logs of that burst are fetched forwards from just before it and the walk carries on
+// from before the burst once the two pages overlap. The api can only be anchored at a timestamp, so the logs in the middle
+// of a burst that fills both pages without an overlap can't be reached and the walk stops with ErrBurstTooLarge
+func paginateBackwards[T any](ctx context.Context, options GetLogsOptions, query pageQuery[T], send func(page []T, oldestLogTimestamp time.Time)) error {
+	anchor := options.endDate()
+
+	// the logs at the anchor that were already sent
+	sent := logMultiset{}
+
+	// the logs at the anchor that are already saved when resuming, they are the ones closest to the anchor
+	savedAtAnchor := 0
+
+	if !options.ResumeFromTimestamp.IsZero() {
+		savedAtAnchor = options.ResumeSavedAtTimestamp
+	}
+
+	loopCount := 0
+	retrier := newRetrier(options.RetryPolicy)
+
+	for {
+		page, err := fetchPage(ctx, retrier, func() ([]T, error) {
+			return query.before(ctx, anchor, MAX_LOG_FETCH)
+		})
+		if err != nil {
+			return err
+		}
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if len(page) == 0 {
+			if loopCount == 0 {
+				return ErrNoLogsFound
+			}
+
+			// nothing is left between the anchor and the start of the time range
+			return nil
+		}
+
+		timestamps, err := parseTimestamps(page, query.timestampOf)
+		if err != nil {
+			return err
+		}
+
+		oldest := timestamps[0]
+
+		full := len(page) == MAX_LOG_FETCH
+		burst := full && oldest.Equal(timestamps[len(timestamps)-1])
+
+		// a query that can't page forwards can't reach the logs of the burst that didn't fit in the page
+		if burst && query.after == nil {
+			return fmt.Errorf("%w: %s", ErrBurstTooLarge, oldest.Format(time.RFC3339Nano))
+		}
+
+		if burst {
+			older, err := fetchPage(ctx, retrier, func() ([]T, error) {
+				return query.after(ctx, oldest.Add(-time.Nanosecond), oldest, MAX_LOG_FETCH)
+			})
+			if err != nil {
+				return err
+			}
+
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			older, err = burstLogs(older, oldest, query.timestampOf)
+			if err != nil {
+				return err
+			}
+
+			page, err = mergeBurst(older, page, oldest, query.keyOf)
+			if err != nil {
+				return err
+			}
+
+			timestamps, err = parseTimestamps(page, query.timestampOf)
+			if err != nil {
+				return err
+			}
+		}
+
+		// the saved logs are the newest at the anchor, a burst is only whole once it was merged
+		for i := len(page) - 1; i >= 0 && savedAtAnchor > 0 && timestamps[i].Equal(anchor); i-- {
+			sent.add(query.keyOf(page[i]))
+			savedAtAnchor--
+		}
+
+		newLogs := make([]T, 0, len(page))
+
+		for i, log := range page {
+			if timestamps[i].Equal(anchor) && sent.take(query.keyOf(log)) {
+				continue
+			}
+
+			newLogs = append(newLogs, log)
+		}
+
+		if len(newLogs) > 0 {
+			send(newLogs, oldest)
+		}
+
+		// a short page means there is nothing older left to fetch
+		if !full {
+			return nil
+		}
+
+		sent = logMultiset{}
+
+		if burst {
+			// the whole burst was sent, carry on from before it
+			anchor = oldest.Add(-time.Nanosecond)
+		} else {
+			anchor = oldest
+
+			for i := 0; i < len(page) && timestamps[i].Equal(oldest); i++ {
+				sent.add(query.keyOf(page[i]))
+			}
+		}
+
+		loopCount++
+	}
+}
+
+// paginateForwards walks from options.CatchUpFromTimestamp to the end of the time range, oldest page first.
+//
+// Every page is anchored just before the newest log of the previous one, so the logs that share that timestamp
+// come back again and the ones that were already sent are dropped by their content. A page that shares one timestamp
+// is completed with the newest logs of that burst, after which the walk carries on from after the burst. Like paging backwards,
+// a burst that fills both pages without an overlap stops the walk with ErrBurstTooLarge
+func paginateForwards[T any](ctx context.Context, options GetLogsOptions, query pageQuery[T], send func(page []T, oldestLogTimestamp time.Time)) error {
+	if options.CatchUpFromTimestamp.IsZero() {
+		return ErrCatchUpFromRequired
+	}
+
+	anchor := options.CatchUpFromTimestamp.UTC()
+
+	// fixed up front so logs that keep coming in don't keep the catch up going forever
+	until := options.endDate()
+
+	// the logs after the anchor that were already sent
+	sent := logMultiset{}
+
+	// the logs at the anchor that are already saved, the others at the anchor still have to be downloaded
+	savedAtAnchor := options.CatchUpSavedAtTimestamp
+	savedTimestamp := anchor
+
+	if savedAtAnchor > 0 {
+		anchor = anchor.Add(-time.Nanosecond)
+	}
+
+	retrier := newRetrier(options.RetryPolicy)
+
+	for {
+		page, err := fetchPage(ctx, retrier, func() ([]T, error) {
+			return query.after(ctx, anchor, until, MAX_LOG_FETCH)
+		})
+		if err != nil {
+			return err
+		}
+
+		if ctx.Err() != nil || len(page) == 0 {
+			return nil
+		}
+
+		timestamps, err := parseTimestamps(page, query.timestampOf)
+		if err != nil {
+			return err
+		}
+
+		newest := timestamps[len(timestamps)-1]
+
+		full := len(page) == MAX_LOG_FETCH
+		burst := full && newest.Equal(timestamps[0])
+
+		if burst && query.before == nil {
+			return fmt.Errorf("%w: %s", ErrBurstTooLarge, newest.Format(time.RFC3339Nano))
+		}
+
+		if burst {
+			newer, err := fetchPage(ctx, retrier, func() ([]T, error) {
+				return query.before(ctx, newest, MAX_LOG_FETCH)
+			})
+			if err != nil {
+				return err
+			}
+
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			newer, err = burstLogs(newer, newest, query.timestampOf)
+			if err != nil {
+				return err
+			}
+
+			page, err = mergeBurst(page, newer, newest, query.keyOf)
+			if err != nil {
+				return err
+			}
+
+			timestamps, err = parseTimestamps(page, query.timestampOf)
+			if err != nil {
+				return err
+			}
+		}
+
+		// the saved logs are the oldest at their timestamp
+		for i := 0; i < len(page) && savedAtAnchor > 0 && timestamps[i].Equal(savedTimestamp); i++ {
+			sent.add(query.keyOf(page[i]))
+			savedAtAnchor--
+		}
+
+		newLogs := make([]T, 0, len(page))
+
+		for i, log := range page {
+			// logs at the anchor are already in the log file or were part of the previous page
+			if !timestamps[i].After(anchor) || sent.take(query.keyOf(log)) {
+				continue
+			}
+
+			newLogs = append(newLogs, log)
+		}
+
+		if len(newLogs) > 0 {
+			oldestLogTimestamp, err := time.Parse(time.RFC3339Nano, query.timestampOf(newLogs[0]))
+			if err != nil {
+				return fmt.Errorf("%w: %w", ErrFailedToParseTimestamp, err)
+			}
+
+			send(newLogs, oldestLogTimestamp)
+		}
+
+		// a short page means there is nothing newer left to fetch
+		if !full {
+			return nil
+		}
+
+		sent = logMultiset{}
+
+		if burst {
+			// the whole burst was sent, carry on from after it
+			anchor = newest
+		} else {
+			anchor = newest.Add(-time.Nanosecond)
+
+			for i := len(page) - 1; i >= 0 && timestamps[i].Equal(newest); i-- {
+				sent.add(query.keyOf(page[i]))
+			}
+		}
+	}
+}
+
+// fetchPage calls fetch until it succeeds, retrying the failures that can be retried,
+// a nil page without an error means the context was cancelled
+func fetchPage[T any](ctx context.Context, retrier *retrier, fetch func() ([]T, error)) ([]T, error) {
+	for {
+		if ctx.Err() != nil {
+			return nil, nil
+		}
+
+		page, err := fetch()
+		if err == nil {
+			// reset the failed attempts on a successful fetch
+			retrier.reset()
+
+			return page, nil
+		}
+
+		// transient errors are retried with a growing delay, errors that retrying won't fix stop right away
+		if err := retrier.retry(ctx, err); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// mergeBurst combines the oldest and the newest logs of a burst that share one timestamp. The api returns a burst
+// in the same order every time, so the newest logs of older are the oldest of newer where the pages overlap and
+// they are kept once, which also keeps the same line logged more than once at the same time.
+// Two full pages that don't overlap leave out the logs between them, which can't be fetched, so that is an ErrBurstTooLarge
+func mergeBurst[T any](older []T, newer []T, timestamp time.Time, keyOf func(T) string) ([]T, error) {
+	olderKeys := make([]string, len(older))
+
+	for i, log := range older {
+		olderKeys[i] = keyOf(log)
+	}
+
+	newerKeys := make([]string, len(newer))
+
+	for i, log := range newer {
+		newerKeys[i] = keyOf(log)
+	}
+
+	overlap := min(len(older), len(newer))
+
+	for ; overlap > 0; overlap-- {
+		if slices.Equal(olderKeys[len(olderKeys)-overlap:], newerKeys[:overlap]) {
+			break
+		}
+	}
+
+	if overlap == 0 && len(older) == MAX_LOG_FETCH && len(newer) == MAX_LOG_FETCH {
+		return nil, fmt.Errorf("%w: %s", ErrBurstTooLarge, timestamp.Format(time.RFC3339Nano))
+	}
+
+	return append(older, newer[overlap:]...), nil
+}
+
+// burstLogs keeps the logs of a page that are part of the burst at timestamp
+func burstLogs[T any](page []T, timestamp time.Time, timestampOf func(T) string) ([]T, error) {
+	timestamps, err := parseTimestamps(page, timestampOf)
+	if err != nil {
+		return nil, err
+	}
+
+	burst := make([]T, 0, len(page))
+
+	for i, log := range page {
+		if timestamps[i].Equal(timestamp) {
+			burst = append(burst, log)
+		}
+	}
+
+	return burst, nil
+}
+
+func parseTimestamps[T any](page []T, timestampOf func(T) string) ([]time.Time, error) {
+	timestamps := make([]time.Time, len(page))
+
+	for i, log := range page {
+		timestamp, err := time.Parse(time.RFC3339Nano, timestampOf(log))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrFailedToParseTimestamp, err)
+		}
+
+		timestamps[i] = timestamp
+	}
+
+	return timestamps, nil
+}
+
+// environmentLogKey identifies an environment log by its content
+func environmentLogKey(log *EnvironmentLogsEnvironmentLogsLog) string {
+	key := strings.Builder{}
+
+	key.WriteString(log.Timestamp)
+	key.WriteByte(0)
+	key.WriteString(log.Severity)
+	key.WriteByte(0)
+	key.WriteString(log.Message)
+
+	for _, attribute := range log.Attributes {
+		key.WriteByte(0)
+		key.WriteString(attribute.Key)
+		key.WriteByte('=')
+		key.WriteString(attribute.Value)
+	}
+
+	if log.Tags != nil {
+		key.WriteByte(0)
+		key.WriteString(log.Tags.DeploymentInstanceId)
+		key.WriteByte(0)
+		key.WriteString(log.Tags.PluginId)
+	}
+
+	return key.String()
+}
+
+// httpLogKey identifies an http log, every request has its own id
+func httpLogKey(log *HttpLogsHttpLogsHttpLog) string {
+	return log.Timestamp + "\x00" + log.RequestId
+}
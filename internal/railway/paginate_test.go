@@ -0,0 +1,283 @@
+package railway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fakeLog is a log of the fake api, only its timestamp and message matter for paging
+type fakeLog struct {
+	timestamp time.Time
+	message   string
+}
+
+var fakeBase = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// fakeLogs returns one log at every second offset, repeated offsets make a burst that shares that timestamp
+func fakeLogs(offsets ...int) []fakeLog {
+	logs := make([]fakeLog, len(offsets))
+
+	for i, offset := range offsets {
+		logs[i] = fakeLog{timestamp: fakeBase.Add(time.Duration(offset) * time.Second), message: fmt.Sprintf("log-%d", i)}
+	}
+
+	return logs
+}
+
+// burst returns the offset repeated count times
+func burst(offset int, count int) []int {
+	return slices.Repeat([]int{offset}, count)
+}
+
+// fakeQuery pages through the logs the way the api does, the logs are ordered oldest first and a burst
+// keeps the same order in every page. Without forwards the query can only page backwards
+func fakeQuery(logs []fakeLog, since time.Time, forwards bool) pageQuery[fakeLog] {
+	query := pageQuery[fakeLog]{
+		before: func(_ context.Context, anchor time.Time, limit int) ([]fakeLog, error) {
+			page := []fakeLog{}
+
+			for _, log := range logs {
+				if !log.timestamp.After(anchor) && !log.timestamp.Before(since) {
+					page = append(page, log)
+				}
+			}
+
+			return page[max(len(page)-limit, 0):], nil
+		},
+		timestampOf: func(log fakeLog) string { return log.timestamp.Format(time.RFC3339Nano) },
+		keyOf:       func(log fakeLog) string { return log.timestamp.Format(time.RFC3339Nano) + "\x00" + log.message },
+	}
+
+	if forwards {
+		query.after = func(_ context.Context, anchor time.Time, until time.Time, limit int) ([]fakeLog, error) {
+			page := []fakeLog{}
+
+			for _, log := range logs {
+				if log.timestamp.After(anchor) && !log.timestamp.After(until) && len(page) < limit {
+					page = append(page, log)
+				}
+			}
+
+			return page, nil
+		}
+	}
+
+	return query
+}
+
+// withPageSize makes every page hold size logs for the test, so bursts of a few logs fill a page
+func withPageSize(t *testing.T, size int) {
+	original := MAX_LOG_FETCH
+	MAX_LOG_FETCH = size
+	t.Cleanup(func() { MAX_LOG_FETCH = original })
+}
+
+// requireSentOnce fails the test unless every wanted log was sent exactly once and nothing else was sent
+func requireSentOnce(t *testing.T, sent []fakeLog, want []fakeLog) {
+	t.Helper()
+
+	counts := map[fakeLog]int{}
+
+	for _, log := range sent {
+		counts[log]++
+	}
+
+	for _, log := range want {
+		if counts[log] != 1 {
+			t.Errorf("expected %s at %s to be sent once, it was sent %d times", log.message, log.timestamp.Format(time.RFC3339), counts[log])
+		}
+
+		delete(counts, log)
+	}
+
+	for log, count := range counts {
+		t.Errorf("expected %s at %s not to be sent, it was sent %d times", log.message, log.timestamp.Format(time.RFC3339), count)
+	}
+}
+
+// requireSentAtMostOnce fails the test when a log was sent more than once
+func requireSentAtMostOnce(t *testing.T, sent []fakeLog) {
+	t.Helper()
+
+	counts := map[fakeLog]int{}
+
+	for _, log := range sent {
+		counts[log]++
+
+		if counts[log] == 2 {
+			t.Errorf("expected %s at %s to be sent at most once", log.message, log.timestamp.Format(time.RFC3339))
+		}
+	}
+}
+
+func TestPaginateBackwards(t *testing.T) {
+	tests := []struct {
+		name string
+		logs []fakeLog
+
+		// the logs the api can only page backwards through, like build and plugin logs
+		windowed bool
+
+		// resume from the timestamp at the offset with the newest saved logs at it already saved
+		resumeAt    int
+		resumeSaved int
+
+		// the index of the first log that is expected, the ones before it are older than the time range
+		since   int
+		wantErr error
+	}{
+		{name: "no bursts", logs: fakeLogs(1, 2, 3, 4, 5, 6, 7, 8, 9, 10)},
+		{name: "logs sharing a timestamp at the edge of a page", logs: fakeLogs(slices.Concat([]int{1, 2, 3}, burst(4, 3), []int{5, 6, 7})...)},
+		{name: "burst of exactly one page", logs: fakeLogs(slices.Concat([]int{1, 2}, burst(3, 4), []int{4, 5})...)},
+		{name: "burst of more than one page", logs: fakeLogs(slices.Concat([]int{1, 2}, burst(3, 6), []int{4, 5})...)},
+		{name: "burst of just under two pages", logs: fakeLogs(slices.Concat([]int{1, 2}, burst(3, 7), []int{4, 5})...)},
+		{name: "burst of more than two pages", logs: fakeLogs(slices.Concat([]int{1, 2}, burst(3, 9), []int{4, 5})...), wantErr: ErrBurstTooLarge},
+		{name: "burst at the oldest log", logs: fakeLogs(slices.Concat(burst(1, 6), []int{2, 3, 4, 5})...)},
+		{name: "burst at the newest log", logs: fakeLogs(slices.Concat([]int{1, 2, 3}, burst(4, 6))...)},
+		{name: "burst of one page that can only be paged backwards", logs: fakeLogs(slices.Concat([]int{1, 2}, burst(3, 4), []int{4, 5})...), windowed: true, wantErr: ErrBurstTooLarge},
+		{name: "burst shorter than a page that can only be paged backwards", logs: fakeLogs(slices.Concat([]int{1, 2}, burst(3, 3), []int{4, 5, 6})...), windowed: true},
+		{name: "resume inside a burst of more than one page", logs: fakeLogs(slices.Concat([]int{1, 2}, burst(3, 6), []int{4, 5})...), resumeAt: 3, resumeSaved: 2},
+		{name: "resume after the newest log at the timestamp", logs: fakeLogs(1, 2, 3, 3, 4, 5, 6, 7), resumeAt: 3, resumeSaved: 2},
+		{name: "resume at a timestamp without saved logs", logs: fakeLogs(1, 2, 3, 4, 5, 6, 7), resumeAt: 5},
+		{name: "since cuts off the oldest logs", logs: fakeLogs(1, 2, 3, 4, 5, 6, 7, 8), since: 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			withPageSize(t, 4)
+
+			options := GetLogsOptions{Until: fakeBase.Add(time.Hour)}
+			want := test.logs
+
+			if test.since > 0 {
+				options.Since = test.logs[test.since].timestamp
+				want = want[test.since:]
+			}
+
+			if test.resumeAt > 0 {
+				options.ResumeFromTimestamp = fakeBase.Add(time.Duration(test.resumeAt) * time.Second)
+				options.ResumeSavedAtTimestamp = test.resumeSaved
+
+				// everything newer than the resumed timestamp and the newest logs at it are already saved
+				want = slices.DeleteFunc(slices.Clone(want), func(log fakeLog) bool {
+					return log.timestamp.After(options.ResumeFromTimestamp)
+				})
+				want = want[:len(want)-test.resumeSaved]
+			}
+
+			sent := []fakeLog{}
+
+			err := paginateBackwards(context.Background(), options, fakeQuery(test.logs, options.startDate(), !test.windowed), func(page []fakeLog, oldestLogTimestamp time.Time) {
+				if !oldestLogTimestamp.Equal(page[0].timestamp) {
+					t.Errorf("expected the oldest log of the page at %s, got %s", page[0].timestamp, oldestLogTimestamp)
+				}
+
+				sent = append(sent, page...)
+			})
+
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Fatalf("expected %v, got %v", test.wantErr, err)
+				}
+
+				requireSentAtMostOnce(t, sent)
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			requireSentOnce(t, sent, want)
+		})
+	}
+}
+
+func TestPaginateForwards(t *testing.T) {
+	tests := []struct {
+		name string
+		logs []fakeLog
+
+		// catch up from the timestamp at the offset with the oldest saved logs at it already saved
+		catchUpFrom  int
+		catchUpSaved int
+
+		wantErr error
+	}{
+		{name: "no bursts", logs: fakeLogs(1, 2, 3, 4, 5, 6, 7, 8, 9, 10), catchUpFrom: 2, catchUpSaved: 1},
+		{name: "logs sharing a timestamp at the edge of a page", logs: fakeLogs(slices.Concat([]int{1, 2, 3, 4}, burst(5, 3), []int{6, 7})...), catchUpFrom: 1, catchUpSaved: 1},
+		{name: "burst of exactly one page", logs: fakeLogs(slices.Concat([]int{1, 2}, burst(3, 4), []int{4, 5})...), catchUpFrom: 1, catchUpSaved: 1},
+		{name: "burst of more than one page", logs: fakeLogs(slices.Concat([]int{1, 2}, burst(3, 6), []int{4, 5})...), catchUpFrom: 1, catchUpSaved: 1},
+		{name: "burst of more than two pages", logs: fakeLogs(slices.Concat([]int{1, 2}, burst(3, 9), []int{4, 5})...), catchUpFrom: 1, catchUpSaved: 1, wantErr: ErrBurstTooLarge},
+		{name: "continue inside a burst of more than one page", logs: fakeLogs(slices.Concat([]int{1, 2}, burst(3, 6), []int{4, 5})...), catchUpFrom: 3, catchUpSaved: 2},
+		{name: "burst at the newest log", logs: fakeLogs(slices.Concat([]int{1, 2, 3}, burst(4, 6))...), catchUpFrom: 1, catchUpSaved: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			withPageSize(t, 4)
+
+			options := GetLogsOptions{
+				Until:                   fakeBase.Add(time.Hour),
+				CatchUpFromTimestamp:    fakeBase.Add(time.Duration(test.catchUpFrom) * time.Second),
+				CatchUpSavedAtTimestamp: test.catchUpSaved,
+			}
+
+			// everything older than the timestamp and the oldest logs at it are already saved
+			saved := slices.IndexFunc(test.logs, func(log fakeLog) bool {
+				return !log.timestamp.Before(options.CatchUpFromTimestamp)
+			})
+
+			want := test.logs[saved+test.catchUpSaved:]
+
+			sent := []fakeLog{}
+
+			err := paginateForwards(context.Background(), options, fakeQuery(test.logs, options.startDate(), true), func(page []fakeLog, oldestLogTimestamp time.Time) {
+				sent = append(sent, page...)
+			})
+
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Fatalf("expected %v, got %v", test.wantErr, err)
+				}
+
+				requireSentAtMostOnce(t, sent)
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			requireSentOnce(t, sent, want)
+		})
+	}
+}
+
+func TestPaginateBackwardsKeepsRepeatedLogs(t *testing.T) {
+	withPageSize(t, 4)
+
+	// a service can log the same line more than once at the same time
+	logs := fakeLogs(slices.Concat([]int{1, 2}, burst(3, 6), []int{4, 5})...)
+	logs[3].message = logs[2].message
+	logs[6].message = logs[2].message
+
+	sent := []fakeLog{}
+
+	err := paginateBackwards(context.Background(), GetLogsOptions{Until: fakeBase.Add(time.Hour)}, fakeQuery(logs, time.Unix(0, 0), true), func(page []fakeLog, _ time.Time) {
+		sent = append(sent, page...)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sent) != len(logs) {
+		t.Fatalf("expected all %d logs to be sent, got %d", len(logs), len(sent))
+	}
+}
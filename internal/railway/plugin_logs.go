@@ -0,0 +1,43 @@
+package railway
+
+import (
+	"context"
+	"time"
+)
+
+func GetAllPluginLogsBlocking(ctx context.Context, railwayClient *RailwayClient, logs chan<- LogLinesResponse, options GetLogsOptions) error {
+	if options.PluginId == "" {
+		return ErrPluginIdRequired
+	}
+
+	if options.EnvironmentId == "" {
+		return ErrEnvironmentIdRequired
+	}
+
+	return getAllWindowedLogsBlocking(ctx, logs, options, func(ctx context.Context, endDate time.Time, limit int) ([]*EnvironmentLogsEnvironmentLogsLog, error) {
+		logsResponse, err := PluginLogs(ctx, railwayClient,
+			endDate,               // end date
+			options.EnvironmentId, // environment id
+			options.Filter,        // filter
+			limit,                 // limit
+			options.PluginId,      // plugin id
+			options.startDate(),   // start date (Unix epoch unless --since is set)
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		return logsResponse.PluginLogs, nil
+	})
+}
+
+func GetAllPluginLogsAsync(ctx context.Context, railwayClient *RailwayClient, logs chan<- LogLinesResponse, options GetLogsOptions) {
+	go func() {
+		if err := GetAllPluginLogsBlocking(ctx, railwayClient, logs, options); err != nil {
+			options.ErrorChannel <- err
+			return
+		}
+
+		options.DoneChannel <- true
+	}()
+}
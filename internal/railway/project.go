@@ -0,0 +1,35 @@
+package railway
+
+import (
+	"context"
+	"fmt"
+)
+
+// GetProjectTargets returns the project name and every environment and service pair of the project
+func GetProjectTargets(ctx context.Context, railwayClient *RailwayClient, projectId string) (string, []ProjectTarget, error) {
+	if projectId == "" {
+		return "", nil, ErrProjectIdRequired
+	}
+
+	projectResponse, err := Project(ctx, railwayClient, projectId)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %w", ErrFailedToGetProject, err)
+	}
+
+	project := projectResponse.Project
+
+	targets := []ProjectTarget{}
+
+	for _, environment := range project.Environments.Edges {
+		for _, service := range project.Services.Edges {
+			targets = append(targets, ProjectTarget{
+				EnvironmentId:   environment.Node.Id,
+				EnvironmentName: environment.Node.Name,
+				ServiceId:       service.Node.Id,
+				ServiceName:     service.Node.Name,
+			})
+		}
+	}
+
+	return project.Name, targets, nil
+}
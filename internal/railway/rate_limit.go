@@ -0,0 +1,166 @@
+package railway
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/buger/jsonparser"
+)
+
+var (
+	RATE_LIMIT_DEFAULT_WAIT = time.Minute // used when the api doesn't say when the quota resets
+	RATE_LIMIT_MIN_WAIT     = time.Second // keeps a rate limited request from being retried straight away
+)
+
+// rateLimiter keeps track of the rate limit quota the api reports, it is shared by every request of a client
+// so a request that runs into the limit holds back all the others until the quota resets
+type rateLimiter struct {
+	resetAt     time.Time
+	onRateLimit func(resetAt time.Time)
+
+	mu sync.Mutex
+}
+
+// wait blocks until the quota has reset or the context is done
+func (l *rateLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	resetAt := l.resetAt
+	l.mu.Unlock()
+
+	delay := time.Until(resetAt)
+	if delay <= 0 {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(delay):
+		return nil
+	}
+}
+
+// observe reads the quota from the response, the returned bool reports if the request was rate limited and has to be sent again
+func (l *rateLimiter) observe(resp *http.Response) (bool, error) {
+	now := time.Now()
+
+	limited := resp.StatusCode == http.StatusTooManyRequests
+
+	// the api can also report the rate limit as a graphql error, so the body has to be checked as well
+	if !limited {
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return false, err
+		}
+
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+
+		limited = hasRateLimitError(body)
+	}
+
+	resetAt, known := rateLimitResetAt(resp.Header, now)
+
+	if !limited {
+		// the quota is used up, hold off the next requests until it resets instead of running into the limit
+		if known && resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			l.holdUntil(resetAt)
+		}
+
+		return false, nil
+	}
+
+	if !known {
+		resetAt = now.Add(RATE_LIMIT_DEFAULT_WAIT)
+	}
+
+	resetAt = later(resetAt, now.Add(RATE_LIMIT_MIN_WAIT))
+
+	l.holdUntil(resetAt)
+
+	l.mu.Lock()
+	onRateLimit := l.onRateLimit
+	l.mu.Unlock()
+
+	if onRateLimit != nil {
+		onRateLimit(resetAt)
+	}
+
+	return true, nil
+}
+
+// holdUntil holds back every request until the given time, an earlier reset never shortens a longer wait
+func (l *rateLimiter) holdUntil(resetAt time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.resetAt = later(l.resetAt, resetAt)
+}
+
+// rateLimitResetAt returns when the quota resets according to the Retry-After or X-RateLimit-Reset header
+func rateLimitResetAt(header http.Header, now time.Time) (time.Time, bool) {
+	// Retry-After is either a number of seconds or a http date
+	if retryAfter := strings.TrimSpace(header.Get("Retry-After")); retryAfter != "" {
+		if seconds, err := strconv.ParseFloat(retryAfter, 64); err == nil {
+			return now.Add(time.Duration(seconds * float64(time.Second))), true
+		}
+
+		if date, err := http.ParseTime(retryAfter); err == nil {
+			return date, true
+		}
+	}
+
+	// X-RateLimit-Reset is a unix timestamp in seconds or milliseconds, a number of seconds, or a date
+	if reset := strings.TrimSpace(header.Get("X-RateLimit-Reset")); reset != "" {
+		if value, err := strconv.ParseFloat(reset, 64); err == nil {
+			switch {
+			case value > 1e12:
+				return time.UnixMilli(int64(value)), true
+			case value > 1e9:
+				return time.Unix(int64(value), 0), true
+			default:
+				return now.Add(time.Duration(value * float64(time.Second))), true
+			}
+		}
+
+		if date, err := time.Parse(time.RFC3339Nano, reset); err == nil {
+			return date, true
+		}
+
+		if date, err := http.ParseTime(reset); err == nil {
+			return date, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// hasRateLimitError reports if the graphql response contains an error about the rate limit
+func hasRateLimitError(body []byte) bool {
+	limited := false
+
+	jsonparser.ArrayEach(body, func(value []byte, _ jsonparser.ValueType, _ int, _ error) {
+		message, _ := jsonparser.GetString(value, "message")
+		code, _ := jsonparser.GetString(value, "extensions", "code")
+
+		if strings.Contains(strings.ToLower(message), "rate limit") || strings.EqualFold(code, "RATE_LIMITED") || strings.EqualFold(code, "TOO_MANY_REQUESTS") {
+			limited = true
+		}
+	}, "errors")
+
+	return limited
+}
+
+func later(a time.Time, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+
+	return b
+}
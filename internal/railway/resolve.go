@@ -0,0 +1,247 @@
+package railway
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/dustin/go-humanize/english"
+	"github.com/google/uuid"
+)
+
+// ResolvedTarget holds the ids of the project, environment and service that were provided by name or id
+type ResolvedTarget struct {
+	ProjectId     string
+	EnvironmentId string
+	ServiceId     string
+}
+
+// named is a project, environment or service, which can be referred to by either its id or its name
+type named struct {
+	id   string
+	name string
+}
+
+// resolvedMatch is a combination of project, environment and service that matches every reference
+type resolvedMatch struct {
+	project     named
+	environment named
+	service     named
+}
+
+// ResolveTarget turns the project, environment and service references into ids, each one can be an id, a name or empty.
+// Ids are used as they are when nothing needs to be looked up, names are looked up in the project,
+// or in every project the token can see when no project is provided
+func ResolveTarget(ctx context.Context, railwayClient *RailwayClient, project string, environment string, service string) (ResolvedTarget, error) {
+	if isId(project) && isId(environment) && isId(service) {
+		return ResolvedTarget{ProjectId: project, EnvironmentId: environment, ServiceId: service}, nil
+	}
+
+	projects, err := getProjectTrees(ctx, railwayClient, project)
+	if err != nil {
+		return ResolvedTarget{}, err
+	}
+
+	projects, err = matchProjects(projects, project)
+	if err != nil {
+		return ResolvedTarget{}, err
+	}
+
+	matches := []resolvedMatch{}
+
+	for _, projectTree := range projects {
+		for _, environmentNode := range matchNamed(environmentsOf(projectTree), environment) {
+			for _, serviceNode := range matchNamed(servicesOf(projectTree), service) {
+				matches = append(matches, resolvedMatch{
+					project:     named{id: projectTree.Id, name: projectTree.Name},
+					environment: environmentNode,
+					service:     serviceNode,
+				})
+			}
+		}
+	}
+
+	switch {
+	case len(matches) == 0:
+		return ResolvedTarget{}, notFoundError(projects, environment, service)
+	case len(matches) > 1:
+		return ResolvedTarget{}, ambiguousError(matches, environment)
+	}
+
+	return ResolvedTarget{
+		ProjectId:     matches[0].project.id,
+		EnvironmentId: matches[0].environment.id,
+		ServiceId:     matches[0].service.id,
+	}, nil
+}
+
+// getProjectTrees fetches the project when it is given by id, and every project the token can see otherwise
+func getProjectTrees(ctx context.Context, railwayClient *RailwayClient, project string) ([]ProjectTree, error) {
+	if project != "" && isId(project) {
+		projectResponse, err := Project(ctx, railwayClient, project)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrFailedToGetProject, err)
+		}
+
+		return []ProjectTree{projectResponse.Project.ProjectTree}, nil
+	}
+
+	projectsResponse, err := Projects(ctx, railwayClient)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToListProjects, err)
+	}
+
+	projects := []ProjectTree{}
+
+	for _, edge := range projectsResponse.Projects.Edges {
+		projects = append(projects, edge.Node.ProjectTree)
+	}
+
+	return projects, nil
+}
+
+// matchProjects keeps the projects the reference matches, a project name has to match exactly one project
+func matchProjects(projects []ProjectTree, project string) ([]ProjectTree, error) {
+	if project == "" {
+		return projects, nil
+	}
+
+	candidates := []named{}
+
+	for _, projectTree := range projects {
+		candidates = append(candidates, named{id: projectTree.Id, name: projectTree.Name})
+	}
+
+	matched := matchNamed(candidates, project)
+
+	switch {
+	case len(matched) == 0:
+		return nil, fmt.Errorf("%w: %q, the projects are: %s", ErrProjectNotFound, project, listNamed(candidates, ""))
+	case len(matched) > 1:
+		return nil, fmt.Errorf("%w: %q matches the projects: %s", ErrAmbiguousProject, project, listNamed(matched, ""))
+	}
+
+	return slices.DeleteFunc(projects, func(projectTree ProjectTree) bool {
+		return projectTree.Id != matched[0].id
+	}), nil
+}
+
+// matchNamed returns the candidates the reference matches, by id or by name ignoring case, an empty reference matches nothing in particular
+func matchNamed(candidates []named, reference string) []named {
+	if reference == "" {
+		return []named{{}}
+	}
+
+	matched := []named{}
+
+	for _, candidate := range candidates {
+		if candidate.id == reference || strings.EqualFold(candidate.name, reference) {
+			matched = append(matched, candidate)
+		}
+	}
+
+	return matched
+}
+
+func environmentsOf(projectTree ProjectTree) []named {
+	environments := []named{}
+
+	for _, edge := range projectTree.Environments.Edges {
+		environments = append(environments, named{id: edge.Node.Id, name: edge.Node.Name})
+	}
+
+	return environments
+}
+
+func servicesOf(projectTree ProjectTree) []named {
+	services := []named{}
+
+	for _, edge := range projectTree.Services.Edges {
+		services = append(services, named{id: edge.Node.Id, name: edge.Node.Name})
+	}
+
+	return services
+}
+
+// notFoundError lists the environments or services that could have been meant, of the projects that were searched
+func notFoundError(projects []ProjectTree, environment string, service string) error {
+	environmentCandidates := []string{}
+	serviceCandidates := []string{}
+
+	for _, projectTree := range projects {
+		// the services are only candidates in the projects the environment was found in
+		if environment != "" && len(matchNamed(environmentsOf(projectTree), environment)) == 0 {
+			environmentCandidates = append(environmentCandidates, listNamed(environmentsOf(projectTree), projectTree.Name))
+			continue
+		}
+
+		serviceCandidates = append(serviceCandidates, listNamed(servicesOf(projectTree), projectTree.Name))
+	}
+
+	if len(serviceCandidates) == 0 {
+		return fmt.Errorf("%w: %q, the environments are: %s", ErrEnvironmentNotFound, environment, strings.Join(environmentCandidates, ", "))
+	}
+
+	return fmt.Errorf("%w: %q, the services are: %s", ErrServiceNotFound, service, strings.Join(serviceCandidates, ", "))
+}
+
+// ambiguousError lists the environments or services the names match more than once
+func ambiguousError(matches []resolvedMatch, environment string) error {
+	environments := []string{}
+	services := []string{}
+
+	for _, match := range matches {
+		if description := describeNamed(match.environment, match.project.name); !slices.Contains(environments, description) {
+			environments = append(environments, description)
+		}
+
+		if description := describeNamed(match.service, match.project.name); !slices.Contains(services, description) {
+			services = append(services, description)
+		}
+	}
+
+	if environment != "" && len(environments) > 1 {
+		return fmt.Errorf("%w: it matches %s, use the project or an id to pick one", ErrAmbiguousEnvironment, english.WordSeries(environments, "and"))
+	}
+
+	return fmt.Errorf("%w: it matches %s, use the project or an id to pick one", ErrAmbiguousService, english.WordSeries(services, "and"))
+}
+
+// listNamed lists the names and ids of the candidates, including the project they belong to when one is given
+func listNamed(candidates []named, projectName string) string {
+	if len(candidates) == 0 {
+		if projectName != "" {
+			return fmt.Sprintf("none in project %s", projectName)
+		}
+
+		return "none"
+	}
+
+	descriptions := []string{}
+
+	for _, candidate := range candidates {
+		descriptions = append(descriptions, describeNamed(candidate, projectName))
+	}
+
+	return strings.Join(descriptions, ", ")
+}
+
+func describeNamed(candidate named, projectName string) string {
+	if projectName != "" {
+		return fmt.Sprintf("%s (%s, project %s)", candidate.name, candidate.id, projectName)
+	}
+
+	return fmt.Sprintf("%s (%s)", candidate.name, candidate.id)
+}
+
+// isId reports if the reference is empty or already an id
+func isId(reference string) bool {
+	if reference == "" {
+		return true
+	}
+
+	_, err := uuid.Parse(reference)
+
+	return err == nil
+}
@@ -0,0 +1,228 @@
+package railway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Khan/genqlient/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// ErrorClass is the kind of failure a request ran into, it decides if the request is worth sending again
+type ErrorClass int
+
+const (
+	ErrorClassUnknown ErrorClass = iota
+	ErrorClassTransientNetwork
+	ErrorClassServer
+	ErrorClassRateLimit
+	ErrorClassAuth
+	ErrorClassBadFilter
+)
+
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrorClassTransientNetwork:
+		return "network error"
+	case ErrorClassServer:
+		return "server error"
+	case ErrorClassRateLimit:
+		return "rate limited"
+	case ErrorClassAuth:
+		return "authentication failed"
+	case ErrorClassBadFilter:
+		return "invalid filter"
+	default:
+		return "unknown error"
+	}
+}
+
+// Fatal reports if sending the request again can't change the outcome
+func (c ErrorClass) Fatal() bool {
+	return c == ErrorClassAuth || c == ErrorClassBadFilter
+}
+
+// FatalError is returned when a request fails in a way that retrying won't fix, such as a bad token or filter
+type FatalError struct {
+	Class ErrorClass
+	Err   error
+}
+
+func (e *FatalError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Class, e.Err)
+}
+
+func (e *FatalError) Unwrap() error {
+	return e.Err
+}
+
+// RetryPolicy decides how often and how long to wait before a failed request is sent again
+type RetryPolicy struct {
+	// MaxAttempts is the number of times a request is sent before giving up
+	MaxAttempts int
+
+	// the wait doubles after every failed attempt, starting at BaseDelay and never going over MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: MAX_RETRY_COUNT,
+	BaseDelay:   time.Second,
+	MaxDelay:    30 * time.Second,
+}
+
+// Delay returns how long to wait after the given number of failed attempts, half of the wait is
+// random so parallel downloads that failed at the same time don't retry at the same time
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	delay := p.BaseDelay
+
+	for range attempt - 1 {
+		if delay >= p.MaxDelay {
+			break
+		}
+
+		delay *= 2
+	}
+
+	delay = min(delay, p.MaxDelay)
+
+	if delay <= 0 {
+		return 0
+	}
+
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// ClassifyError sorts the error of a request into the kind of failure it is. The status code and the codes of the graphql
+// errors decide first, the message is only a last resort and is checked for the transient failures before the fatal ones
+func ClassifyError(err error) ErrorClass {
+	var httpErr *graphql.HTTPError
+
+	if errors.As(err, &httpErr) {
+		switch {
+		case httpErr.StatusCode == http.StatusTooManyRequests:
+			return ErrorClassRateLimit
+		case httpErr.StatusCode == http.StatusUnauthorized, httpErr.StatusCode == http.StatusForbidden:
+			return ErrorClassAuth
+		case httpErr.StatusCode >= 500:
+			return ErrorClassServer
+		}
+	}
+
+	var netErr net.Error
+	var syntaxErr *json.SyntaxError
+
+	// dropped connections and responses that were cut off
+	if errors.As(err, &netErr) || errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return ErrorClassTransientNetwork
+	}
+
+	graphqlErrors := gqlerror.List{}
+
+	if httpErr != nil {
+		graphqlErrors = httpErr.Response.Errors
+	} else {
+		errors.As(err, &graphqlErrors)
+	}
+
+	for _, graphqlErr := range graphqlErrors {
+		if class := classifyErrorCode(graphqlErr); class != ErrorClassUnknown {
+			return class
+		}
+	}
+
+	return classifyErrorMessage(strings.ToLower(err.Error()))
+}
+
+// classifyErrorCode sorts a graphql error by the code in its extensions
+func classifyErrorCode(graphqlErr *gqlerror.Error) ErrorClass {
+	code, _ := graphqlErr.Extensions["code"].(string)
+
+	switch strings.ToUpper(code) {
+	case "RATE_LIMITED", "TOO_MANY_REQUESTS":
+		return ErrorClassRateLimit
+	case "INTERNAL_SERVER_ERROR", "SERVICE_UNAVAILABLE", "TIMEOUT":
+		return ErrorClassServer
+	case "UNAUTHENTICATED", "UNAUTHORIZED", "FORBIDDEN":
+		return ErrorClassAuth
+	case "BAD_USER_INPUT":
+		// the filter is the only argument that isn't checked before the request is sent
+		if strings.Contains(strings.ToLower(graphqlErr.Message), "filter") {
+			return ErrorClassBadFilter
+		}
+	}
+
+	return ErrorClassUnknown
+}
+
+// invalidFilterMessageRe matches the words an error about a filter that can't be used is worded with
+var invalidFilterMessageRe = regexp.MustCompile(`\b(invalid|parse|parsing|syntax|unknown)\b`)
+
+// classifyErrorMessage sorts an error that came without a status code or graphql code by its message,
+// a message that could be either is taken to be transient so the request is sent again
+func classifyErrorMessage(message string) ErrorClass {
+	switch {
+	case strings.Contains(message, "rate limit"):
+		return ErrorClassRateLimit
+	case strings.Contains(message, "internal server error"), strings.Contains(message, "timed out"), strings.Contains(message, "temporarily unavailable"):
+		return ErrorClassServer
+	case strings.Contains(message, "not authorized"), strings.Contains(message, "unauthorized"), strings.Contains(message, "invalid token"), strings.Contains(message, "forbidden"):
+		return ErrorClassAuth
+	case strings.Contains(message, "filter") && invalidFilterMessageRe.MatchString(message):
+		return ErrorClassBadFilter
+	}
+
+	return ErrorClassUnknown
+}
+
+// retrier keeps track of the failed attempts of a single request in a fetch loop
+type retrier struct {
+	policy   RetryPolicy
+	attempts int
+}
+
+func newRetrier(policy RetryPolicy) *retrier {
+	if policy.MaxAttempts < 1 {
+		policy = DefaultRetryPolicy
+	}
+
+	return &retrier{policy: policy}
+}
+
+// retry waits before the request is sent again, or returns the error to stop with when the request shouldn't be retried,
+// it returns nil without waiting when the context is done so the fetch loop can stop
+func (r *retrier) retry(ctx context.Context, err error) error {
+	class := ClassifyError(err)
+
+	if class.Fatal() {
+		return fmt.Errorf("%w: %w", ErrFailedToGetLogs, &FatalError{Class: class, Err: err})
+	}
+
+	r.attempts++
+
+	if r.attempts >= r.policy.MaxAttempts {
+		return fmt.Errorf("%w: %s after %d attempts: %w", ErrFailedToGetLogs, class, r.attempts, err)
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(r.policy.Delay(r.attempts)):
+	}
+
+	return nil
+}
+
+// reset is called after a successful request so the next failure starts over
+func (r *retrier) reset() {
+	r.attempts = 0
+}
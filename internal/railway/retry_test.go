@@ -0,0 +1,62 @@
+package railway
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/Khan/genqlient/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+func TestClassifyError(t *testing.T) {
+	graphqlError := func(message string, code string) *gqlerror.Error {
+		graphqlErr := &gqlerror.Error{Message: message}
+
+		if code != "" {
+			graphqlErr.Extensions = map[string]any{"code": code}
+		}
+
+		return graphqlErr
+	}
+
+	httpError := func(statusCode int, errs ...*gqlerror.Error) error {
+		return &graphql.HTTPError{StatusCode: statusCode, Response: graphql.Response{Errors: errs}}
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want ErrorClass
+	}{
+		{"too many requests", httpError(http.StatusTooManyRequests), ErrorClassRateLimit},
+		{"unauthorized", httpError(http.StatusUnauthorized), ErrorClassAuth},
+		{"server error", httpError(http.StatusBadGateway), ErrorClassServer},
+		{"server error quoting the filter", httpError(http.StatusInternalServerError, graphqlError("invalid filter in query", "")), ErrorClassServer},
+		{"rate limited quoting the filter", httpError(http.StatusTooManyRequests, graphqlError("rate limit hit for filter @service:x", "")), ErrorClassRateLimit},
+		{"dropped connection", fmt.Errorf("wrapped: %w", io.ErrUnexpectedEOF), ErrorClassTransientNetwork},
+		{"rate limit code", gqlerror.List{graphqlError("slow down", "RATE_LIMITED")}, ErrorClassRateLimit},
+		{"server code mentioning the filter", gqlerror.List{graphqlError("failed to run filter", "INTERNAL_SERVER_ERROR")}, ErrorClassServer},
+		{"auth code", gqlerror.List{graphqlError("who are you", "UNAUTHENTICATED")}, ErrorClassAuth},
+		{"bad filter code", gqlerror.List{graphqlError("filter is not valid", "BAD_USER_INPUT")}, ErrorClassBadFilter},
+		{"bad input that isn't the filter", gqlerror.List{graphqlError("deployment id is not valid", "BAD_USER_INPUT")}, ErrorClassUnknown},
+		{"bad request status with a bad filter code", httpError(http.StatusBadRequest, graphqlError("filter is not valid", "BAD_USER_INPUT")), ErrorClassBadFilter},
+		{"rate limit message", gqlerror.List{graphqlError("Rate limit exceeded", "")}, ErrorClassRateLimit},
+		{"rate limit message quoting the filter", gqlerror.List{graphqlError("rate limit exceeded for invalid filter", "")}, ErrorClassRateLimit},
+		{"server message quoting the filter", gqlerror.List{graphqlError("internal server error while parsing filter", "")}, ErrorClassServer},
+		{"auth message", gqlerror.List{graphqlError("Not Authorized", "")}, ErrorClassAuth},
+		{"bad filter message", gqlerror.List{graphqlError("Invalid filter: unexpected token", "")}, ErrorClassBadFilter},
+		{"message that only mentions the filter", gqlerror.List{graphqlError("no logs matched the filter", "")}, ErrorClassUnknown},
+		{"unknown", errors.New("something else"), ErrorClassUnknown},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ClassifyError(test.err); got != test.want {
+				t.Fatalf("expected %s, got %s", test.want, got)
+			}
+		})
+	}
+}
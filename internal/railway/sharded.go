@@ -0,0 +1,222 @@
+package railway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TimeWindow is a part of the requested time range, logs at Start are included and logs at End are not,
+// except for the last window which includes the end of the time range
+type TimeWindow struct {
+	Start time.Time
+	End   time.Time
+
+	// ResumeFromTimestamp and ResumeSavedAtTimestamp continue a window that was partly downloaded before
+	ResumeFromTimestamp    time.Time
+	ResumeSavedAtTimestamp int
+
+	// Done skips a window that was completely downloaded before
+	Done bool
+}
+
+// GetAllDeploymentLogsShardedBlocking splits the time range into options.Shards windows and downloads them at the same time,
+// every window is paginated on its own so the logs of a single window arrive newest first but the windows arrive in any order
+func GetAllDeploymentLogsShardedBlocking(ctx context.Context, railwayClient *RailwayClient, logs chan<- LogLinesResponse, options GetLogsOptions) error {
+	environmentId, filter, err := resolveEnvironmentLogsFilter(ctx, railwayClient, options)
+	if err != nil {
+		return err
+	}
+
+	// the oldest log marks the start of the time range when --since isn't set, so no window is spent on a stretch without logs
+	findOldestLog := func() (string, error) {
+		afterDate := options.endDate().Format(time.RFC3339Nano)
+		anchorDate := options.startDate().Format(time.RFC3339Nano)
+
+		logsResponse, err := EnvironmentLogsAfter(ctx, railwayClient,
+			afterDate,     // after date
+			1,             // after limit
+			anchorDate,    // anchor date
+			environmentId, // environment id
+			filter,        // filter
+		)
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrFailedToGetLogs, err)
+		}
+
+		if len(logsResponse.EnvironmentLogs) == 0 {
+			return "", ErrNoLogsFound
+		}
+
+		return logsResponse.EnvironmentLogs[0].Timestamp, nil
+	}
+
+	// the environment is already known, so the windows don't have to look it up again
+	options.EnvironmentId = environmentId
+
+	return getAllShardedLogsBlocking(ctx, options, findOldestLog, func(ctx context.Context, options GetLogsOptions) error {
+		return GetAllDeploymentLogsBlocking(ctx, railwayClient, logs, options)
+	})
+}
+
+func GetAllDeploymentLogsShardedAsync(ctx context.Context, railwayClient *RailwayClient, logs chan<- LogLinesResponse, options GetLogsOptions) {
+	go func() {
+		if err := GetAllDeploymentLogsShardedBlocking(ctx, railwayClient, logs, options); err != nil {
+			options.ErrorChannel <- err
+			return
+		}
+
+		options.DoneChannel <- true
+	}()
+}
+
+// GetAllHttpLogsShardedBlocking splits the time range into options.Shards windows and downloads the http logs of them at the same time
+func GetAllHttpLogsShardedBlocking(ctx context.Context, railwayClient *RailwayClient, logs chan<- LogLinesResponse, options GetLogsOptions) error {
+	if options.DeploymentId == "" {
+		return ErrDeploymentIdRequired
+	}
+
+	// the oldest log marks the start of the time range when --since isn't set
+	findOldestLog := func() (string, error) {
+		afterDate := options.endDate().Format(time.RFC3339Nano)
+		anchorDate := options.startDate().Format(time.RFC3339Nano)
+
+		logsResponse, err := HttpLogsAfter(ctx, railwayClient,
+			afterDate,            // after date
+			1,                    // after limit
+			anchorDate,           // anchor date
+			options.DeploymentId, // deployment id
+			options.Filter,       // filter
+		)
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrFailedToGetLogs, err)
+		}
+
+		if len(logsResponse.HttpLogs) == 0 {
+			return "", ErrNoLogsFound
+		}
+
+		return logsResponse.HttpLogs[0].Timestamp, nil
+	}
+
+	return getAllShardedLogsBlocking(ctx, options, findOldestLog, func(ctx context.Context, options GetLogsOptions) error {
+		return GetAllHttpLogsBlocking(ctx, railwayClient, logs, options)
+	})
+}
+
+func GetAllHttpLogsShardedAsync(ctx context.Context, railwayClient *RailwayClient, logs chan<- LogLinesResponse, options GetLogsOptions) {
+	go func() {
+		if err := GetAllHttpLogsShardedBlocking(ctx, railwayClient, logs, options); err != nil {
+			options.ErrorChannel <- err
+			return
+		}
+
+		options.DoneChannel <- true
+	}()
+}
+
+// getAllShardedLogsBlocking downloads every window of the time range with getWindowLogs at the same time,
+// the first window that fails stops the others. The time range is split from the oldest log up to its end,
+// unless options.Windows holds the windows of an interrupted download to continue
+func getAllShardedLogsBlocking(ctx context.Context, options GetLogsOptions, findOldestLog func() (string, error), getWindowLogs func(ctx context.Context, options GetLogsOptions) error) error {
+	windows := options.Windows
+
+	if windows == nil {
+		oldestLogTimestamp, err := findOldestLog()
+		if err != nil {
+			return err
+		}
+
+		start, err := time.Parse(time.RFC3339Nano, oldestLogTimestamp)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrFailedToParseTimestamp, err)
+		}
+
+		// start just before the oldest log so it can't fall on the edge of the first window
+		windows = splitTimeRange(start.UTC().Add(-time.Millisecond), options.endDate(), options.Shards)
+
+		// only the last window resumes so the logs that are already saved at the end of the time range are skipped
+		windows[len(windows)-1].ResumeFromTimestamp = options.ResumeFromTimestamp
+		windows[len(windows)-1].ResumeSavedAtTimestamp = options.ResumeSavedAtTimestamp
+	}
+
+	if options.OnWindows != nil {
+		options.OnWindows(windows)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make([]error, len(windows))
+	waitGroup := sync.WaitGroup{}
+
+	for i, window := range windows {
+		if window.Done {
+			continue
+		}
+
+		waitGroup.Add(1)
+
+		go func() {
+			defer waitGroup.Done()
+
+			windowOptions := options
+			windowOptions.Shard = i
+			windowOptions.Since = window.Start
+			windowOptions.Until = window.End
+			windowOptions.ResumeFromTimestamp = window.ResumeFromTimestamp
+			windowOptions.ResumeSavedAtTimestamp = window.ResumeSavedAtTimestamp
+
+			// the windows share their edges, so the logs at an edge only belong to the window after it
+			if i < len(windows)-1 {
+				windowOptions.Until = window.End.Add(-time.Nanosecond)
+			}
+
+			// a window without any logs is expected when the logs come in bursts
+			if err := getWindowLogs(ctx, windowOptions); err != nil && !errors.Is(err, ErrNoLogsFound) {
+				errs[i] = err
+
+				cancel() // the logs would have a gap, so stop the other windows
+
+				return
+			}
+
+			// a cancelled window stops early without an error
+			if ctx.Err() == nil && options.OnShardDone != nil {
+				options.OnShardDone(i)
+			}
+		}()
+	}
+
+	waitGroup.Wait()
+
+	return errors.Join(errs...)
+}
+
+// splitTimeRange splits the time range into at most n windows of the same length
+func splitTimeRange(start time.Time, end time.Time, n int) []TimeWindow {
+	// windows shorter than a millisecond would only add requests
+	n = min(n, int(end.Sub(start)/time.Millisecond))
+
+	if n <= 1 {
+		return []TimeWindow{{Start: start, End: end}}
+	}
+
+	step := end.Sub(start) / time.Duration(n)
+
+	windows := make([]TimeWindow, n)
+
+	for i := range windows {
+		windows[i] = TimeWindow{
+			Start: start.Add(step * time.Duration(i)),
+			End:   start.Add(step * time.Duration(i+1)),
+		}
+	}
+
+	// the last window always ends at the end of the time range, rounding can leave it slightly short
+	windows[n-1].End = end
+
+	return windows
+}
@@ -0,0 +1,189 @@
+package railway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	TAIL_RECONNECT_MIN_DELAY = time.Second
+	TAIL_RECONNECT_MAX_DELAY = 30 * time.Second
+	TAIL_DEDUPE_WINDOW       = time.Minute // how far behind the newest log a replayed log is still recognised
+)
+
+// tailPosition keeps track of the recently streamed logs so the logs that are replayed after a reconnect can be skipped
+type tailPosition struct {
+	timestamp time.Time
+
+	// the logs streamed within the dedupe window of the newest log, keyed by their timestamp, severity and message
+	seen     map[string]time.Time
+	prunedAt time.Time
+}
+
+// TailLogsBlocking streams the logs of a deployment, service or whole environment as they arrive until the context is done,
+// the websocket connection is started again whenever it drops and the logs that were missed in the meantime are caught up on
+func TailLogsBlocking(ctx context.Context, railwayClient *RailwayClient, wsClient *WebSocketClient, logs chan<- LogLinesResponse, options GetLogsOptions) error {
+	environmentId, filter, err := resolveEnvironmentLogsFilter(ctx, railwayClient, options)
+	if err != nil {
+		return err
+	}
+
+	position := &tailPosition{seen: map[string]time.Time{}}
+
+	reconnectDelay := TAIL_RECONNECT_MIN_DELAY
+
+	for {
+		received, err := tailUntilDisconnected(ctx, wsClient, logs, environmentId, filter, position)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+
+		// errors returned by the api itself won't go away by reconnecting
+		if errors.Is(err, ErrFailedToGetLogs) {
+			return err
+		}
+
+		// only back off further while the connection keeps failing
+		if received {
+			reconnectDelay = TAIL_RECONNECT_MIN_DELAY
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(reconnectDelay):
+		}
+
+		reconnectDelay = min(reconnectDelay*2, TAIL_RECONNECT_MAX_DELAY)
+	}
+}
+
+// tailUntilDisconnected streams logs over a single websocket connection, the returned bool reports if any logs were received
+func tailUntilDisconnected(ctx context.Context, wsClient *WebSocketClient, logs chan<- LogLinesResponse, environmentId string, filter string, position *tailPosition) (bool, error) {
+	errChan, err := wsClient.Start(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	anchorDate := ""
+	afterLimit := 0
+
+	// after a reconnect, start from the newest log we have and catch up on everything after it
+	if !position.timestamp.IsZero() {
+		anchorDate = position.timestamp.UTC().Format(time.RFC3339Nano)
+		afterLimit = MAX_LOG_FETCH
+	}
+
+	dataChan, _, err := StreamEnvironmentLogs(ctx, wsClient,
+		"",            // after date
+		afterLimit,    // after limit
+		anchorDate,    // anchor date
+		"",            // before date
+		0,             // before limit
+		environmentId, // environment id
+		filter,        // filter
+	)
+	if err != nil {
+		wsClient.Close()
+		return false, err
+	}
+
+	// the data channel is closed by the client once the connection is gone, drain it so nothing is left blocked
+	defer func() {
+		wsClient.Close()
+
+		for range dataChan {
+		}
+	}()
+
+	received := false
+
+	for {
+		select {
+		case <-ctx.Done():
+			return received, nil
+		case err := <-errChan:
+			return received, err
+		case response, ok := <-dataChan:
+			if !ok {
+				return received, ErrWebSocketConnectionLost
+			}
+
+			if len(response.Errors) > 0 {
+				return received, fmt.Errorf("%w: %w", ErrFailedToGetLogs, response.Errors)
+			}
+
+			if response.Data == nil {
+				continue
+			}
+
+			newLogs, err := position.filterNew(response.Data.EnvironmentLogs)
+			if err != nil {
+				return received, err
+			}
+
+			if len(newLogs) == 0 {
+				continue
+			}
+
+			received = true
+
+			// parse the first log timestamp
+			firstLogTimestamp, err := time.Parse(time.RFC3339Nano, newLogs[0].Timestamp)
+			if err != nil {
+				return received, fmt.Errorf("%w: %w", ErrFailedToParseTimestamp, err)
+			}
+
+			select {
+			case logs <- LogLinesResponse{Logs: newLogs, OldestLogTimestamp: firstLogTimestamp}:
+			case <-ctx.Done():
+				return received, nil
+			}
+		}
+	}
+}
+
+// filterNew drops the logs that were already streamed, logs can arrive slightly out of order from multiple replicas
+// so they are only compared against each other rather than against the newest timestamp
+func (p *tailPosition) filterNew(logs []*EnvironmentLogsEnvironmentLogsLog) ([]*EnvironmentLogsEnvironmentLogsLog, error) {
+	newLogs := make([]*EnvironmentLogsEnvironmentLogsLog, 0, len(logs))
+
+	for _, log := range logs {
+		timestamp, err := time.Parse(time.RFC3339Nano, log.Timestamp)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrFailedToParseTimestamp, err)
+		}
+
+		key := log.Timestamp + "\x00" + log.Severity + "\x00" + log.Message
+
+		if _, ok := p.seen[key]; ok {
+			continue
+		}
+
+		p.seen[key] = timestamp
+
+		if timestamp.After(p.timestamp) {
+			p.timestamp = timestamp
+		}
+
+		newLogs = append(newLogs, log)
+	}
+
+	// forget the logs that are too old to be replayed, once per window so busy streams don't walk the map on every message
+	if p.timestamp.Sub(p.prunedAt) > TAIL_DEDUPE_WINDOW {
+		for key, timestamp := range p.seen {
+			if timestamp.Before(p.timestamp.Add(-TAIL_DEDUPE_WINDOW)) {
+				delete(p.seen, key)
+			}
+		}
+
+		p.prunedAt = p.timestamp
+	}
+
+	return newLogs, nil
+}
@@ -0,0 +1,299 @@
+package railway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// wsStandIn is a local graphql-transport-ws stand-in of the Railway API, every connection answers its subscription
+// with the pages that were set up for it. A connection that isn't the last one is dropped once its pages were sent
+type wsStandIn struct {
+	server *httptest.Server
+
+	connections [][][]*EnvironmentLogsEnvironmentLogsLog
+
+	// the variables of every subscription, in the order they were received
+	variables []map[string]any
+	mu        sync.Mutex
+}
+
+func newWSStandIn(t *testing.T, connections ...[][]*EnvironmentLogsEnvironmentLogsLog) *wsStandIn {
+	standIn := &wsStandIn{connections: connections}
+
+	upgrader := websocket.Upgrader{Subprotocols: []string{wsSubprotocol}}
+
+	standIn.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("failed to upgrade the connection: %s", err)
+			return
+		}
+
+		defer conn.Close()
+
+		standIn.serve(t, conn)
+	}))
+
+	t.Cleanup(standIn.server.Close)
+
+	return standIn
+}
+
+// endpoint is the websocket url of the stand-in
+func (s *wsStandIn) endpoint() string {
+	return "ws" + strings.TrimPrefix(s.server.URL, "http")
+}
+
+func (s *wsStandIn) serve(t *testing.T, conn *websocket.Conn) {
+	message := wsMessage{}
+
+	if err := conn.ReadJSON(&message); err != nil || message.Type != wsMessageConnectionInit {
+		t.Errorf("expected %s, got %+v (%v)", wsMessageConnectionInit, message, err)
+		return
+	}
+
+	if err := conn.WriteJSON(wsMessage{Type: wsMessageConnectionAck}); err != nil {
+		return
+	}
+
+	if err := conn.ReadJSON(&message); err != nil || message.Type != wsMessageSubscribe {
+		t.Errorf("expected %s, got %+v (%v)", wsMessageSubscribe, message, err)
+		return
+	}
+
+	request := struct {
+		Variables map[string]any `json:"variables"`
+	}{}
+
+	if err := json.Unmarshal(message.Payload, &request); err != nil {
+		t.Errorf("failed to parse the subscription: %s", err)
+		return
+	}
+
+	s.mu.Lock()
+	connection := len(s.variables)
+	s.variables = append(s.variables, request.Variables)
+	s.mu.Unlock()
+
+	// the client has to answer pings to keep the connection open
+	if err := conn.WriteJSON(wsMessage{Type: wsMessagePing}); err != nil {
+		return
+	}
+
+	if err := conn.ReadJSON(&message); err != nil || message.Type != wsMessagePong {
+		t.Errorf("expected %s, got %+v (%v)", wsMessagePong, message, err)
+		return
+	}
+
+	if connection >= len(s.connections) {
+		return
+	}
+
+	for _, page := range s.connections[connection] {
+		payload, err := json.Marshal(map[string]any{"data": map[string]any{"environmentLogs": page}})
+		if err != nil {
+			t.Errorf("failed to encode the page: %s", err)
+			return
+		}
+
+		if err := conn.WriteJSON(wsMessage{ID: message.ID, Type: wsMessageNext, Payload: payload}); err != nil {
+			return
+		}
+	}
+
+	// the last connection stays open until the client goes away
+	if connection == len(s.connections)-1 {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// subscriptions returns the variables of every subscription that was received so far
+func (s *wsStandIn) subscriptions() []map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]map[string]any{}, s.variables...)
+}
+
+func tailLog(timestamp string, message string) *EnvironmentLogsEnvironmentLogsLog {
+	return &EnvironmentLogsEnvironmentLogsLog{Timestamp: timestamp, Severity: "info", Message: message}
+}
+
+// tailMessages streams the logs of the stand-in until want logs were received and returns their messages
+func tailMessages(t *testing.T, standIn *wsStandIn, want int) []string {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	railwayClient := NewAuthedClient(standIn.server.URL, Credentials{})
+	wsClient := NewAuthedWebSocketClient(standIn.endpoint(), Credentials{})
+
+	logs := make(chan LogLinesResponse)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- TailLogsBlocking(ctx, railwayClient, wsClient, logs, GetLogsOptions{EnvironmentId: "environment", ServiceId: "service"})
+	}()
+
+	messages := []string{}
+
+	for len(messages) < want {
+		select {
+		case response := <-logs:
+			for _, log := range response.Logs {
+				messages = append(messages, log.Message)
+			}
+		case err := <-done:
+			t.Fatalf("tail stopped after %v: %v", messages, err)
+		case <-ctx.Done():
+			t.Fatalf("timed out after %v", messages)
+		}
+	}
+
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Fatalf("tail returned an error after it was cancelled: %s", err)
+	}
+
+	return messages
+}
+
+func TestTailLogsStreams(t *testing.T) {
+	standIn := newWSStandIn(t, [][]*EnvironmentLogsEnvironmentLogsLog{
+		{tailLog("2024-01-01T00:00:00Z", "a"), tailLog("2024-01-01T00:00:01Z", "b")},
+		{tailLog("2024-01-01T00:00:02Z", "c")},
+	})
+
+	messages := tailMessages(t, standIn, 3)
+
+	if strings.Join(messages, ",") != "a,b,c" {
+		t.Fatalf("expected a,b,c, got %v", messages)
+	}
+
+	subscriptions := standIn.subscriptions()
+
+	if len(subscriptions) != 1 {
+		t.Fatalf("expected a single subscription, got %d", len(subscriptions))
+	}
+
+	// the first connection starts at the newest log
+	if _, ok := subscriptions[0]["anchorDate"]; ok {
+		t.Fatalf("expected the first subscription to have no anchor, got %v", subscriptions[0])
+	}
+}
+
+func TestTailLogsReconnects(t *testing.T) {
+	originalDelay := TAIL_RECONNECT_MIN_DELAY
+	TAIL_RECONNECT_MIN_DELAY = time.Millisecond
+	t.Cleanup(func() { TAIL_RECONNECT_MIN_DELAY = originalDelay })
+
+	standIn := newWSStandIn(t,
+		[][]*EnvironmentLogsEnvironmentLogsLog{
+			{tailLog("2024-01-01T00:00:00Z", "a"), tailLog("2024-01-01T00:00:01Z", "b")},
+		},
+		// the catch up after the reconnect replays the newest log that was already streamed
+		[][]*EnvironmentLogsEnvironmentLogsLog{
+			{tailLog("2024-01-01T00:00:01Z", "b"), tailLog("2024-01-01T00:00:01Z", "c")},
+			{tailLog("2024-01-01T00:00:02Z", "d")},
+		},
+	)
+
+	messages := tailMessages(t, standIn, 4)
+
+	if strings.Join(messages, ",") != "a,b,c,d" {
+		t.Fatalf("expected every log once, got %v", messages)
+	}
+
+	subscriptions := standIn.subscriptions()
+
+	if len(subscriptions) != 2 {
+		t.Fatalf("expected a subscription for every connection, got %d", len(subscriptions))
+	}
+
+	if subscriptions[1]["anchorDate"] != "2024-01-01T00:00:01Z" {
+		t.Fatalf("expected the reconnect to catch up from the newest streamed log, got %v", subscriptions[1]["anchorDate"])
+	}
+
+	if subscriptions[1]["afterLimit"] != float64(MAX_LOG_FETCH) {
+		t.Fatalf("expected the reconnect to catch up on a whole page, got %v", subscriptions[1]["afterLimit"])
+	}
+}
+
+func TestTailPositionFilterNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		batches [][]*EnvironmentLogsEnvironmentLogsLog
+		want    []string
+	}{
+		{
+			name: "replayed logs are dropped",
+			batches: [][]*EnvironmentLogsEnvironmentLogsLog{
+				{tailLog("2024-01-01T00:00:00Z", "a"), tailLog("2024-01-01T00:00:01Z", "b")},
+				{tailLog("2024-01-01T00:00:01Z", "b"), tailLog("2024-01-01T00:00:02Z", "c")},
+			},
+			want: []string{"a", "b", "c"},
+		},
+		{
+			name: "different logs at the same timestamp are kept",
+			batches: [][]*EnvironmentLogsEnvironmentLogsLog{
+				{tailLog("2024-01-01T00:00:00Z", "a")},
+				{tailLog("2024-01-01T00:00:00Z", "b")},
+			},
+			want: []string{"a", "b"},
+		},
+		{
+			name: "logs that arrive out of order are kept",
+			batches: [][]*EnvironmentLogsEnvironmentLogsLog{
+				{tailLog("2024-01-01T00:00:02Z", "b")},
+				{tailLog("2024-01-01T00:00:01Z", "a")},
+			},
+			want: []string{"b", "a"},
+		},
+		{
+			name: "logs older than the dedupe window are forgotten",
+			batches: [][]*EnvironmentLogsEnvironmentLogsLog{
+				{tailLog("2024-01-01T00:00:00Z", "a")},
+				{tailLog("2024-01-01T00:05:00Z", "b")},
+				{tailLog("2024-01-01T00:00:00Z", "a")},
+			},
+			want: []string{"a", "b", "a"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			position := &tailPosition{seen: map[string]time.Time{}}
+
+			messages := []string{}
+
+			for _, batch := range test.batches {
+				newLogs, err := position.filterNew(batch)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				for _, log := range newLogs {
+					messages = append(messages, log.Message)
+				}
+			}
+
+			if strings.Join(messages, ",") != strings.Join(test.want, ",") {
+				t.Fatalf("expected %v, got %v", test.want, messages)
+			}
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package railway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Khan/genqlient/graphql"
+	"github.com/gorilla/websocket"
+)
+
+// graphql-transport-ws message types
+// https://github.com/enisdenjo/graphql-ws/blob/master/PROTOCOL.md
+const (
+	wsMessageConnectionInit = "connection_init"
+	wsMessageConnectionAck  = "connection_ack"
+	wsMessagePing           = "ping"
+	wsMessagePong           = "pong"
+	wsMessageSubscribe      = "subscribe"
+	wsMessageNext           = "next"
+	wsMessageError          = "error"
+	wsMessageComplete       = "complete"
+
+	wsSubprotocol = "graphql-transport-ws"
+
+	wsConnectionAckTimeout = 30 * time.Second
+)
+
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+type wsSubscription struct {
+	interfaceChan   any
+	forwardDataFunc graphql.ForwardDataFunction
+
+	unsubscribed bool
+}
+
+// WebSocketClient is a graphql-transport-ws client that satisfies genqlient's graphql.WebSocketClient,
+// unlike genqlient's own client it answers the server's pings and can be started again after the connection drops.
+//
+// The data channels of the subscriptions are only ever closed by the goroutine that sends on them, either when the
+// server completes a subscription or when the connection is gone, so they must be drained until they are closed
+type WebSocketClient struct {
+	endpoint    string
+	credentials Credentials
+	dialer      *websocket.Dialer
+
+	conn    *websocket.Conn
+	errChan chan error
+
+	subscriptions      map[string]wsSubscription
+	nextSubscriptionId int
+
+	closing bool
+
+	// guards the connection writes, the subscriptions and the closing state
+	mu sync.Mutex
+}
+
+// NewAuthedWebSocketClient creates a websocket client for the given endpoint, the endpoint can point to
+// a local stand-in of the Railway API since nothing about the connection is specific to Railway
+func NewAuthedWebSocketClient(endpoint string, credentials Credentials) *WebSocketClient {
+	return &WebSocketClient{
+		endpoint:    endpoint,
+		credentials: credentials,
+		dialer: &websocket.Dialer{
+			Proxy:            http.ProxyFromEnvironment,
+			HandshakeTimeout: 30 * time.Second,
+			Subprotocols:     []string{wsSubprotocol},
+		},
+	}
+}
+
+// Start opens a new connection and waits for the server to acknowledge it,
+// errors on the connection are sent on the returned channel until Close is called
+func (c *WebSocketClient) Start(ctx context.Context) (chan error, error) {
+	header := http.Header{}
+	c.credentials.setHeaders(header)
+
+	conn, _, err := c.dialer.DialContext(ctx, c.endpoint, header)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToConnectWebSocket, err)
+	}
+
+	if err := conn.WriteJSON(wsMessage{Type: wsMessageConnectionInit}); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("%w: %w", ErrFailedToConnectWebSocket, err)
+	}
+
+	// the server has a limited amount of time to acknowledge the connection
+	conn.SetReadDeadline(time.Now().Add(wsConnectionAckTimeout))
+
+	for {
+		message := wsMessage{}
+
+		if err := conn.ReadJSON(&message); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("%w: %w", ErrFailedToConnectWebSocket, err)
+		}
+
+		if message.Type == wsMessageConnectionAck {
+			break
+		}
+	}
+
+	conn.SetReadDeadline(time.Time{})
+
+	c.mu.Lock()
+	c.conn = conn
+	c.errChan = make(chan error, 1)
+	c.subscriptions = map[string]wsSubscription{}
+	c.closing = false
+	c.mu.Unlock()
+
+	go c.listen(conn, c.errChan, c.subscriptions)
+
+	return c.errChan, nil
+}
+
+// Close closes the connection and the error channel, it is a no-op if the client was never started
+func (c *WebSocketClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil || c.closing {
+		return nil
+	}
+
+	c.closing = true
+
+	// let the server know we are going away, the connection is closed either way
+	c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+
+	close(c.errChan)
+
+	return c.conn.Close()
+}
+
+// Subscribe sends the subscription to the server, data for it is passed through forwardDataFunc into interfaceChan
+func (c *WebSocketClient) Subscribe(req *graphql.Request, interfaceChan any, forwardDataFunc graphql.ForwardDataFunction) (string, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil || c.closing {
+		return "", ErrWebSocketNotStarted
+	}
+
+	c.nextSubscriptionId++
+
+	subscriptionId := strconv.Itoa(c.nextSubscriptionId)
+
+	if err := c.conn.WriteJSON(wsMessage{ID: subscriptionId, Type: wsMessageSubscribe, Payload: payload}); err != nil {
+		return "", err
+	}
+
+	c.subscriptions[subscriptionId] = wsSubscription{
+		interfaceChan:   interfaceChan,
+		forwardDataFunc: forwardDataFunc,
+	}
+
+	return subscriptionId, nil
+}
+
+// Unsubscribe tells the server to stop the subscription, any data that still arrives for it is dropped
+func (c *WebSocketClient) Unsubscribe(subscriptionId string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	subscription, ok := c.subscriptions[subscriptionId]
+	if !ok || subscription.unsubscribed {
+		return nil
+	}
+
+	subscription.unsubscribed = true
+	c.subscriptions[subscriptionId] = subscription
+
+	return c.conn.WriteJSON(wsMessage{ID: subscriptionId, Type: wsMessageComplete})
+}
+
+// listen reads messages from the connection until it is closed or fails, the subscriptions
+// belong to this connection alone so a restarted client never sees the channels of the previous one
+func (c *WebSocketClient) listen(conn *websocket.Conn, errChan chan error, subscriptions map[string]wsSubscription) {
+	defer c.completeAll(subscriptions)
+
+	for {
+		message := wsMessage{}
+
+		if err := conn.ReadJSON(&message); err != nil {
+			c.fail(errChan, fmt.Errorf("%w: %w", ErrWebSocketConnectionLost, err))
+			return
+		}
+
+		switch message.Type {
+		case wsMessagePing:
+			c.mu.Lock()
+			err := conn.WriteJSON(wsMessage{Type: wsMessagePong})
+			c.mu.Unlock()
+
+			if err != nil {
+				c.fail(errChan, fmt.Errorf("%w: %w", ErrWebSocketConnectionLost, err))
+				return
+			}
+		case wsMessageNext:
+			if err := c.forward(subscriptions, message.ID, message.Payload); err != nil {
+				c.fail(errChan, err)
+				return
+			}
+		case wsMessageError:
+			// error payloads are a list of graphql errors, wrap them so genqlient's forward function can read them
+			if err := c.forward(subscriptions, message.ID, json.RawMessage(fmt.Sprintf(`{"errors":%s}`, message.Payload))); err != nil {
+				c.fail(errChan, err)
+				return
+			}
+
+			c.complete(subscriptions, message.ID)
+		case wsMessageComplete:
+			c.complete(subscriptions, message.ID)
+		}
+	}
+}
+
+// forward passes the payload to the subscription it belongs to
+func (c *WebSocketClient) forward(subscriptions map[string]wsSubscription, subscriptionId string, payload json.RawMessage) error {
+	c.mu.Lock()
+	subscription, ok := subscriptions[subscriptionId]
+	c.mu.Unlock()
+
+	// the subscription was already stopped on our end
+	if !ok || subscription.unsubscribed {
+		return nil
+	}
+
+	return subscription.forwardDataFunc(subscription.interfaceChan, payload)
+}
+
+// complete closes the data channel of a subscription the server has finished
+func (c *WebSocketClient) complete(subscriptions map[string]wsSubscription, subscriptionId string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if subscription, ok := subscriptions[subscriptionId]; ok {
+		reflect.ValueOf(subscription.interfaceChan).Close()
+		delete(subscriptions, subscriptionId)
+	}
+}
+
+// completeAll closes the data channels of every subscription once the connection is gone
+func (c *WebSocketClient) completeAll(subscriptions map[string]wsSubscription) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for subscriptionId, subscription := range subscriptions {
+		reflect.ValueOf(subscription.interfaceChan).Close()
+		delete(subscriptions, subscriptionId)
+	}
+}
+
+// fail reports an error on the connection unless the client is being closed
+func (c *WebSocketClient) fail(errChan chan error, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closing || errChan != c.errChan {
+		return
+	}
+
+	select {
+	case errChan <- err:
+	default:
+	}
+}
@@ -0,0 +1,193 @@
+package tools
+
+import (
+	"bufio"
+	"fmt"
+	"time"
+
+	"main/internal/compression"
+	"main/internal/logline"
+)
+
+// Deduplicator drops the logs that were already written, matched by the hash of their content,
+// and adds that hash to every log that is written as its id when withID is set.
+//
+// Logs come from one source after another, the chunk files of an interrupted download, the download itself and the previous
+// log file that is merged back in. Those only meet at the oldest and the newest timestamp of a source, so only the logs at
+// those timestamps are remembered, counted so a line that was logged more than once at the same time is kept as often as it was logged
+type Deduplicator struct {
+	dedupe bool
+	withID bool
+
+	// the logs at the oldest and the newest timestamp of every finished source, keyed by the unix nano of the timestamp
+	written map[int64]logCounts
+
+	// the logs of the current source at the timestamps in written
+	repeated map[int64]logCounts
+
+	// the logs at the oldest and the newest timestamp of the current source
+	oldest sourceEdge
+	newest sourceEdge
+}
+
+// logCounts counts the logs by their id
+type logCounts map[logline.LogID]int
+
+// sourceEdge is the logs of a source at its oldest or newest timestamp
+type sourceEdge struct {
+	timestamp time.Time
+	logs      logCounts
+}
+
+// NewDeduplicator starts with the logs of the first source
+func NewDeduplicator(dedupe bool, withID bool) *Deduplicator {
+	return &Deduplicator{
+		dedupe:   dedupe,
+		withID:   withID,
+		written:  map[int64]logCounts{},
+		repeated: map[int64]logCounts{},
+	}
+}
+
+// Process returns the log line to write, or nil when the log was already written
+func (d *Deduplicator) Process(line []byte) ([]byte, error) {
+	if !d.dedupe && !d.withID {
+		return line, nil
+	}
+
+	id, timestamp, err := logline.HashLogLine(line)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.dedupe && d.count(timestamp, id) {
+		return nil, nil
+	}
+
+	if d.withID {
+		return logline.SetLogID(line, id)
+	}
+
+	return line, nil
+}
+
+// Seed remembers the logs in the file as written, used when a download continues with the chunk files it wrote before
+func (d *Deduplicator) Seed(filename string) error {
+	return d.seed(filename, false)
+}
+
+// SeedFirstTimestamp remembers the logs at the first timestamp of the file, the only ones of a segmented archive
+// that a resumed download fetches again
+func (d *Deduplicator) SeedFirstTimestamp(filename string) error {
+	return d.seed(filename, true)
+}
+
+func (d *Deduplicator) seed(filename string, firstTimestampOnly bool) error {
+	if !d.dedupe {
+		return nil
+	}
+
+	file, err := compression.Open(filename)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToOpenLogFile, err)
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	firstTimestamp := time.Time{}
+
+	for scanner.Scan() {
+		id, timestamp, err := logline.HashLogLine(scanner.Bytes())
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrFailedToDeduplicateLogLine, err)
+		}
+
+		if firstTimestampOnly && !firstTimestamp.IsZero() && !timestamp.Equal(firstTimestamp) {
+			break
+		}
+
+		firstTimestamp = timestamp
+
+		// the seeded logs are already written, they are only compared with the logs of the sources after the file
+		d.addToEdges(timestamp, id)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToReadFile, err)
+	}
+
+	d.nextSource()
+
+	return nil
+}
+
+// count adds the log to the current source, reporting if it was already written by an earlier source
+func (d *Deduplicator) count(timestamp time.Time, id logline.LogID) bool {
+	d.addToEdges(timestamp, id)
+
+	written, ok := d.written[timestamp.UnixNano()]
+	if !ok {
+		return false
+	}
+
+	repeated, ok := d.repeated[timestamp.UnixNano()]
+	if !ok {
+		repeated = logCounts{}
+		d.repeated[timestamp.UnixNano()] = repeated
+	}
+
+	repeated[id]++
+
+	return repeated[id] <= written[id]
+}
+
+func (d *Deduplicator) addToEdges(timestamp time.Time, id logline.LogID) {
+	if d.oldest.logs == nil || timestamp.Before(d.oldest.timestamp) {
+		d.oldest = sourceEdge{timestamp: timestamp, logs: logCounts{}}
+	}
+
+	if d.newest.logs == nil || timestamp.After(d.newest.timestamp) {
+		d.newest = sourceEdge{timestamp: timestamp, logs: logCounts{}}
+	}
+
+	if timestamp.Equal(d.oldest.timestamp) {
+		d.oldest.logs[id]++
+	}
+
+	if timestamp.Equal(d.newest.timestamp) {
+		d.newest.logs[id]++
+	}
+}
+
+// nextSource finishes the current source, the logs at its edges are now in the output as often as
+// either source had them at that timestamp
+func (d *Deduplicator) nextSource() {
+	edges := []sourceEdge{d.oldest}
+
+	if !d.newest.timestamp.Equal(d.oldest.timestamp) {
+		edges = append(edges, d.newest)
+	}
+
+	for _, edge := range edges {
+		if edge.logs == nil {
+			continue
+		}
+
+		written, ok := d.written[edge.timestamp.UnixNano()]
+		if !ok {
+			written = logCounts{}
+			d.written[edge.timestamp.UnixNano()] = written
+		}
+
+		for id, count := range edge.logs {
+			written[id] = max(written[id], count)
+		}
+	}
+
+	d.repeated = map[int64]logCounts{}
+	d.oldest = sourceEdge{}
+	d.newest = sourceEdge{}
+}
@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func dedupeLine(second int, message string) []byte {
+	return fmt.Appendf(nil, `{"message":%q,"timestamp":"2024-01-01T00:00:%02dZ"}`, message, second)
+}
+
+// processLines returns the messages of the lines the deduplicator lets through
+func processLines(t *testing.T, deduplicator *Deduplicator, lines ...[]byte) []string {
+	t.Helper()
+
+	messages := []string{}
+
+	for _, line := range lines {
+		processed, err := deduplicator.Process(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if processed != nil {
+			messages = append(messages, string(processed))
+		}
+	}
+
+	return messages
+}
+
+func TestDeduplicatorKeepsRepeatsWithinASource(t *testing.T) {
+	deduplicator := NewDeduplicator(true, false)
+
+	written := processLines(t, deduplicator, dedupeLine(3, "a"), dedupeLine(2, "retry"), dedupeLine(2, "retry"), dedupeLine(1, "b"))
+
+	if len(written) != 4 {
+		t.Fatalf("expected every log of a single source to be written, got %v", written)
+	}
+}
+
+func TestDeduplicatorDropsWhatAnEarlierSourceWrote(t *testing.T) {
+	deduplicator := NewDeduplicator(true, false)
+
+	// the download reached the oldest timestamp of the previous log file, which holds the same line twice
+	processLines(t, deduplicator, dedupeLine(5, "x"), dedupeLine(3, "retry"), dedupeLine(3, "other"))
+
+	previous := bytes.Join([][]byte{dedupeLine(3, "retry"), dedupeLine(3, "retry"), dedupeLine(3, "other"), dedupeLine(4, "y")}, []byte{'\n'})
+
+	output := bytes.Buffer{}
+
+	if err := copyLogLines(&output, bytes.NewReader(previous), deduplicator); err != nil {
+		t.Fatal(err)
+	}
+
+	want := string(dedupeLine(3, "retry")) + "\n" + string(dedupeLine(4, "y")) + "\n"
+
+	if output.String() != want {
+		t.Fatalf("expected only the second retry and y to be copied, got %q", output.String())
+	}
+}
+
+func TestDeduplicatorComparesWithSeededChunkFiles(t *testing.T) {
+	chunkFile := filepath.Join(t.TempDir(), "chunk.jsonl")
+
+	chunk := bytes.Join([][]byte{dedupeLine(9, "newest"), dedupeLine(4, "middle"), dedupeLine(2, "edge")}, []byte{'\n'})
+
+	if err := os.WriteFile(chunkFile, chunk, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	deduplicator := NewDeduplicator(true, false)
+
+	if err := deduplicator.Seed(chunkFile); err != nil {
+		t.Fatal(err)
+	}
+
+	// the continued download fetches the oldest timestamp of the chunk file again
+	written := processLines(t, deduplicator, dedupeLine(2, "edge"), dedupeLine(2, "edge"), dedupeLine(1, "older"))
+
+	if strings.Join(written, "\n") != string(dedupeLine(2, "edge"))+"\n"+string(dedupeLine(1, "older")) {
+		t.Fatalf("expected the edge log that was seeded to be dropped once, got %v", written)
+	}
+}
+
+func TestDeduplicatorOnlyRemembersTheEdgesOfASource(t *testing.T) {
+	deduplicator := NewDeduplicator(true, false)
+
+	for second := 59; second >= 0; second-- {
+		processLines(t, deduplicator, dedupeLine(second, "a"), dedupeLine(second, "b"))
+	}
+
+	deduplicator.nextSource()
+
+	if len(deduplicator.written) != 2 {
+		t.Fatalf("expected only the oldest and the newest timestamp to be remembered, got %d", len(deduplicator.written))
+	}
+
+	for timestamp, logs := range deduplicator.written {
+		if len(logs) != 2 {
+			t.Fatalf("expected the two logs at %d to be remembered, got %d", timestamp, len(logs))
+		}
+	}
+}
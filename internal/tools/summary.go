@@ -0,0 +1,102 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Summary is the number of logs in a log file and the timestamps of its oldest and newest log
+type Summary struct {
+	Lines  int64
+	Oldest time.Time
+	Newest time.Time
+}
+
+// Append returns the summary of the log file once the logs of appended were written after the ones it had
+func (s Summary) Append(appended Summary) Summary {
+	if appended.Lines == 0 {
+		return s
+	}
+
+	if s.Lines == 0 {
+		return appended
+	}
+
+	return Summary{Lines: s.Lines + appended.Lines, Oldest: s.Oldest, Newest: appended.Newest}
+}
+
+// SummaryWriter counts the log lines written through it and keeps the first and the last one,
+// so a log file doesn't have to be read again to find out what was written to it
+type SummaryWriter struct {
+	writer io.Writer
+
+	lines int64
+
+	// the first line, it is complete once it ends in a newline
+	first []byte
+
+	// the last complete line followed by the part of the next one that was written so far
+	last []byte
+}
+
+func NewSummaryWriter(writer io.Writer) *SummaryWriter {
+	return &SummaryWriter{writer: writer}
+}
+
+func (w *SummaryWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	p = p[:n]
+
+	w.lines += int64(bytes.Count(p, []byte{'\n'}))
+
+	if !bytes.HasSuffix(w.first, []byte{'\n'}) {
+		if i := bytes.IndexByte(p, '\n'); i >= 0 {
+			w.first = append(w.first, p[:i+1]...)
+		} else {
+			w.first = append(w.first, p...)
+		}
+	}
+
+	w.last = append(w.last, p...)
+
+	// only the last complete line is kept
+	if end := bytes.LastIndexByte(w.last, '\n'); end >= 0 {
+		if start := bytes.LastIndexByte(w.last[:end], '\n') + 1; start > 0 {
+			w.last = append(w.last[:0], w.last[start:]...)
+		}
+	}
+
+	return n, err
+}
+
+// Summary returns the summary of the log lines that were written, the first one is the oldest
+func (w *SummaryWriter) Summary() (Summary, error) {
+	if w.lines == 0 {
+		return Summary{}, nil
+	}
+
+	oldest, err := lineTimestamp(w.first)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	newest, err := lineTimestamp(w.last[:bytes.LastIndexByte(w.last, '\n')])
+	if err != nil {
+		return Summary{}, err
+	}
+
+	return Summary{Lines: w.lines, Oldest: oldest, Newest: newest}, nil
+}
+
+func lineTimestamp(line []byte) (time.Time, error) {
+	logLine := LogLine{}
+
+	if err := json.Unmarshal(line, &logLine); err != nil {
+		return time.Time{}, fmt.Errorf("%w: %w", ErrFailedToParseLogLine, err)
+	}
+
+	return logLine.Timestamp, nil
+}
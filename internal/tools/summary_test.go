@@ -0,0 +1,52 @@
+package tools
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestSummaryWriter(t *testing.T) {
+	content := bytes.Join([][]byte{dedupeLine(1, "oldest"), dedupeLine(2, "a"), dedupeLine(2, "b"), dedupeLine(7, "newest")}, []byte{'\n'})
+	content = append(content, '\n')
+
+	// the lines are split across writes anywhere, like a copy of a chunk file does
+	for _, size := range []int{1, 7, 64, len(content)} {
+		writer := NewSummaryWriter(io.Discard)
+
+		for rest := content; len(rest) > 0; rest = rest[min(size, len(rest)):] {
+			if _, err := writer.Write(rest[:min(size, len(rest))]); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		summary, err := writer.Summary()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := Summary{Lines: 4, Oldest: time.Date(2024, 1, 1, 0, 0, 1, 0, time.UTC), Newest: time.Date(2024, 1, 1, 0, 0, 7, 0, time.UTC)}
+
+		if summary != want {
+			t.Fatalf("expected %+v writing %d bytes at a time, got %+v", want, size, summary)
+		}
+	}
+}
+
+func TestSummaryAppend(t *testing.T) {
+	previous := Summary{Lines: 3, Oldest: time.Unix(10, 0), Newest: time.Unix(20, 0)}
+	appended := Summary{Lines: 2, Oldest: time.Unix(20, 0), Newest: time.Unix(30, 0)}
+
+	if got := previous.Append(appended); got != (Summary{Lines: 5, Oldest: time.Unix(10, 0), Newest: time.Unix(30, 0)}) {
+		t.Fatalf("expected the appended logs to extend the log file, got %+v", got)
+	}
+
+	if got := (Summary{}).Append(appended); got != appended {
+		t.Fatalf("expected an empty log file to hold only the appended logs, got %+v", got)
+	}
+
+	if got := previous.Append(Summary{}); got != previous {
+		t.Fatalf("expected nothing appended to leave the log file as it was, got %+v", got)
+	}
+}
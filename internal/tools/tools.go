@@ -2,93 +2,240 @@ package tools
 
 import (
 	"bufio"
+	"bytes"
+	"cmp"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"main/internal/compression"
 	"main/internal/logline"
 	"main/internal/railway"
 	"os"
 	"path/filepath"
+	"runtime"
 	"slices"
 	"strconv"
+	"strings"
 	"time"
-)
 
-const (
-	TMP_PATH = "./tmp"
+	"github.com/buger/jsonparser"
 )
 
-func FlushLogsToFile(logs []*railway.EnvironmentLogsEnvironmentLogsLog, filename string) error {
+// Flushed is what was written to a chunk file once the duplicates were dropped, the timestamps of its oldest and
+// newest log and how many of the written logs share them
+type Flushed struct {
+	Logs int
+
+	Oldest   time.Time
+	AtOldest int
+	Newest   time.Time
+	AtNewest int
+}
+
+func (f *Flushed) add(timestamp time.Time) {
+	switch {
+	case f.Logs == 0 || timestamp.Before(f.Oldest):
+		f.Oldest, f.AtOldest = timestamp, 1
+	case timestamp.Equal(f.Oldest):
+		f.AtOldest++
+	}
+
+	switch {
+	case f.Logs == 0 || timestamp.After(f.Newest):
+		f.Newest, f.AtNewest = timestamp, 1
+	case timestamp.Equal(f.Newest):
+		f.AtNewest++
+	}
+
+	f.Logs++
+}
+
+func FlushLogsToFile(logs []*railway.EnvironmentLogsEnvironmentLogsLog, filename string, deduplicator *Deduplicator) (Flushed, error) {
+	return flushToFile(logs, filename, logline.ReconstructLogLine, deduplicator)
+}
+
+func FlushLogsWithTagsToFile(logs []*railway.EnvironmentLogsEnvironmentLogsLog, filename string, deduplicator *Deduplicator) (Flushed, error) {
+	return flushToFile(logs, filename, logline.ReconstructLogLineWithTags, deduplicator)
+}
+
+func FlushHttpLogsToFile(logs []*railway.HttpLogsHttpLogsHttpLog, filename string, deduplicator *Deduplicator) (Flushed, error) {
+	return flushToFile(logs, filename, logline.ReconstructHttpLogLine, deduplicator)
+}
+
+// flushToFile reconstructs every log with the given reconstructor and appends it as a json line to the file,
+// returning what was written after dropping the duplicates
+func flushToFile[T any](logs []T, filename string, reconstruct func(T) ([]byte, error), deduplicator *Deduplicator) (Flushed, error) {
 	if len(logs) == 0 {
-		return ErrNoLogsToFlush
+		return Flushed{}, ErrNoLogsToFlush
 	}
 
 	// Create directory path if it doesn't exist
 	dir := filepath.Dir(filename)
 	err := os.MkdirAll(dir, 0755)
 	if err != nil {
-		return fmt.Errorf("failed to create directory path: %w", err)
+		return Flushed{}, fmt.Errorf("failed to create directory path: %w", err)
 	}
 
 	logFile, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
-		return fmt.Errorf("%w: %w", ErrFailedToCreateLogFile, err)
+		return Flushed{}, fmt.Errorf("%w: %w", ErrFailedToCreateLogFile, err)
 	}
 
 	defer logFile.Close()
 
+	// a chunk that is compressed gets a gzip member or zstd frame for every page that is appended to it
+	writer, err := compression.NewWriter(logFile, compression.ForFile(filename))
+	if err != nil {
+		return Flushed{}, fmt.Errorf("%w: %w", ErrFailedToCreateLogFile, err)
+	}
+
+	flushed, err := writeLogs(writer, logs, reconstruct, deduplicator)
+	if err != nil {
+		return flushed, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return flushed, fmt.Errorf("%w: %w", ErrFailedToWriteLogLine, err)
+	}
+
+	// the chunk is checkpointed once it is written, so it has to be on disk by then
+	if err := logFile.Sync(); err != nil {
+		return flushed, fmt.Errorf("%w: %w", ErrFailedToWriteLogLine, err)
+	}
+
+	return flushed, nil
+}
+
+// WriteLogs writes the logs as json lines to the writer, used when logs are streamed rather than written in chunks
+func WriteLogs(writer io.Writer, logs []*railway.EnvironmentLogsEnvironmentLogsLog, deduplicator *Deduplicator) (int, error) {
+	written, err := writeLogs(writer, logs, logline.ReconstructLogLine, deduplicator)
+
+	return written.Logs, err
+}
+
+// WriteLogsWithTags writes the logs as json lines to the writer, keeping the tags of every log
+func WriteLogsWithTags(writer io.Writer, logs []*railway.EnvironmentLogsEnvironmentLogsLog, deduplicator *Deduplicator) (int, error) {
+	written, err := writeLogs(writer, logs, logline.ReconstructLogLineWithTags, deduplicator)
+
+	return written.Logs, err
+}
+
+func writeLogs[T any](writer io.Writer, logs []T, reconstruct func(T) ([]byte, error), deduplicator *Deduplicator) (Flushed, error) {
+	written := Flushed{}
+
 	for _, logLine := range logs {
-		logLineJson, err := logline.ReconstructLogLine(logLine)
+		logLineJson, err := reconstruct(logLine)
+		if err != nil {
+			return written, fmt.Errorf("%w: %w", ErrFailedToReconstructLogLine, err)
+		}
+
+		logLineJson, err = deduplicator.Process(logLineJson)
+		if err != nil {
+			return written, fmt.Errorf("%w: %w", ErrFailedToDeduplicateLogLine, err)
+		}
+
+		// the log was already written
+		if logLineJson == nil {
+			continue
+		}
+
+		// the timestamp is read from the line that is written, a log can have an attribute that replaces it
+		rawTimestamp, err := jsonparser.GetString(logLineJson, "timestamp")
+		if err != nil {
+			return written, fmt.Errorf("%w: %w", ErrFailedToParseLogLine, err)
+		}
+
+		timestamp, err := time.Parse(time.RFC3339Nano, rawTimestamp)
 		if err != nil {
-			return fmt.Errorf("%w: %w", ErrFailedToReconstructLogLine, err)
+			return written, fmt.Errorf("%w: %w", ErrFailedToParseLogLine, err)
+		}
+
+		if _, err := writer.Write(append(logLineJson, '\n')); err != nil {
+			return written, fmt.Errorf("%w: %w", ErrFailedToWriteLogLine, err)
 		}
 
-		logFile.Write(logLineJson)
-		logFile.Write([]byte("\n"))
+		written.add(timestamp)
 	}
 
-	return nil
+	return written, nil
+}
+
+// ChunkFileName names a chunk file after the timestamp of its oldest log and the order it was fetched in,
+// chunks that start at the same timestamp are part of a burst of logs that was split over more than one page.
+// A chunk that is compressed with the codec gets its extension
+func ChunkFileName(tmpPath string, oldestLogTimestamp time.Time, sequence int, codec compression.Codec) string {
+	return codec.Named(filepath.Join(tmpPath, fmt.Sprintf("%d-%d.jsonl", oldestLogTimestamp.UTC().UnixNano(), sequence)))
 }
 
-func CombineLogFiles(logFilesLocation string, outputFilename string) error {
-	files, err := filepath.Glob(filepath.Join(logFilesLocation, "*.jsonl"))
+// parseChunkFileName returns the timestamp and sequence a chunk file is named after
+func parseChunkFileName(filename string) (int64, int64) {
+	name := filepath.Base(filename)
+	name = strings.TrimSuffix(name, compression.ForFile(name).Extension())
+
+	unixNano, sequence, _ := strings.Cut(strings.TrimSuffix(name, ".jsonl"), "-")
+
+	unixNanoValue, _ := strconv.ParseInt(unixNano, 10, 64)
+	sequenceValue, _ := strconv.ParseInt(sequence, 10, 64)
+
+	return unixNanoValue, sequenceValue
+}
+
+// CombineLogFiles copies every chunk file into the output in order, newestFirst tells if the chunks were fetched newest first,
+// which decides the order of the chunks that start at the same timestamp. The chunk files are left in place, they are removed
+// with the work directory once the log file was saved
+func CombineLogFiles(logFilesLocation string, output io.Writer, newestFirst bool) error {
+	files, err := chunkFiles(logFilesLocation)
 	if err != nil {
 		return fmt.Errorf("failed to glob log files: %w", err)
 	}
 
+	// chunk files are named after the timestamp of their oldest log, so they can be downloaded in any order
 	slices.SortFunc(files, func(a, b string) int {
-		aUnix, _ := strconv.ParseInt(filepath.Base(a), 10, 64)
-		bUnix, _ := strconv.ParseInt(filepath.Base(b), 10, 64)
-		return int(aUnix - bUnix)
-	})
+		aUnix, aSequence := parseChunkFileName(a)
+		bUnix, bSequence := parseChunkFileName(b)
 
-	outputFile, err := os.OpenFile(outputFilename, os.O_APPEND|os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
-	if err != nil {
-		return fmt.Errorf("%w: %w", ErrFailedToCreateOutputFile, err)
-	}
+		if newestFirst {
+			return cmp.Or(cmp.Compare(aUnix, bUnix), cmp.Compare(bSequence, aSequence))
+		}
 
-	defer outputFile.Close()
+		return cmp.Or(cmp.Compare(aUnix, bUnix), cmp.Compare(aSequence, bSequence))
+	})
 
 	for _, file := range files {
-		f, err := os.OpenFile(file, os.O_RDONLY, 0644)
+		// compressed chunks are copied as json lines, the output is compressed on its own
+		f, err := compression.Open(file)
 		if err != nil {
 			return fmt.Errorf("%w: %w", ErrFailedToReadFile, err)
 		}
 
 		defer f.Close()
 
-		if _, err := io.Copy(outputFile, f); err != nil {
+		if _, err := io.Copy(output, f); err != nil {
 			return fmt.Errorf("%w: %w", ErrFailedToCopyTMPFile, err)
 		}
 
 		f.Close()
+	}
 
-		if err := os.Remove(file); err != nil {
-			return fmt.Errorf("%w: %w", ErrFailedToRemoveLogFile, err)
+	return nil
+}
+
+// chunkFiles returns the chunk files in the tmp path, compressed or not
+func chunkFiles(tmpPath string) ([]string, error) {
+	files := []string{}
+
+	for _, pattern := range []string{"*.jsonl", "*.jsonl.gz", "*.jsonl.zst"} {
+		matches, err := filepath.Glob(filepath.Join(tmpPath, pattern))
+		if err != nil {
+			return nil, err
 		}
+
+		files = append(files, matches...)
 	}
 
-	return nil
+	return files, nil
 }
 
 type LogLine struct {
@@ -96,7 +243,7 @@ type LogLine struct {
 }
 
 func ReadFirstLineTimestamp(filename string) (time.Time, error) {
-	file, err := os.OpenFile(filename, os.O_RDONLY, 0644)
+	file, err := compression.Open(filename)
 	if err != nil {
 		return time.Time{}, fmt.Errorf("%w: %w", ErrFailedToOpenLogFile, err)
 	}
@@ -104,6 +251,7 @@ func ReadFirstLineTimestamp(filename string) (time.Time, error) {
 	defer file.Close()
 
 	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
 
 	if scanner.Scan() {
 		line := scanner.Bytes()
@@ -120,54 +268,567 @@ func ReadFirstLineTimestamp(filename string) (time.Time, error) {
 	return time.Time{}, nil
 }
 
-func FinalLogWrite(filename string, useResume bool) error {
-	if useResume {
-		if err := os.Rename(filename, ("previous_" + filename)); err != nil {
-			return fmt.Errorf("%w: %w", ErrFailedToRenameLogFile, err)
+// CountFirstTimestampLines returns the number of logs at the start of the file that share the timestamp of the first log,
+// a resumed download fetches that timestamp again and skips that many of its logs
+func CountFirstTimestampLines(filename string) (int, error) {
+	file, err := compression.Open(filename)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrFailedToOpenLogFile, err)
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	count := 0
+	firstTimestamp := time.Time{}
+
+	for scanner.Scan() {
+		logLine := LogLine{}
+
+		if err := json.Unmarshal(scanner.Bytes(), &logLine); err != nil {
+			return 0, fmt.Errorf("%w: %w", ErrFailedToParseLogLine, err)
+		}
+
+		if count > 0 && !logLine.Timestamp.Equal(firstTimestamp) {
+			break
 		}
+
+		firstTimestamp = logLine.Timestamp
+		count++
 	}
 
-	if err := CombineLogFiles("./tmp", filename); err != nil {
-		return fmt.Errorf("%w: %w", ErrFailedToCombineLogs, err)
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrFailedToReadFile, err)
 	}
 
-	if useResume {
-		oldLogFile, err := os.OpenFile(("previous_" + filename), os.O_RDONLY, 0644)
+	return count, nil
+}
+
+// CountLastTimestampLines returns the number of logs at the end of the file that share the timestamp of the last log,
+// a catch up fetches that timestamp again and skips that many of its logs. Like ReadLastLineTimestamp, only a compressed file is read from the start
+func CountLastTimestampLines(filename string) (int, error) {
+	codec, err := compression.Detect(filename)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrFailedToOpenLogFile, err)
+	}
+
+	if codec != compression.CodecNone {
+		return countLastTimestampLinesCompressed(filename)
+	}
+
+	file, err := os.OpenFile(filename, os.O_RDONLY, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrFailedToOpenLogFile, err)
+	}
+
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrFailedToReadFile, err)
+	}
+
+	end := info.Size()
+	tail := []byte{}
+	chunk := make([]byte, 64*1024)
+
+	for end > 0 {
+		start := max(end-int64(len(chunk)), 0)
+
+		if _, err := file.ReadAt(chunk[:end-start], start); err != nil {
+			return 0, fmt.Errorf("%w: %w", ErrFailedToReadFile, err)
+		}
+
+		tail = append(slices.Clone(chunk[:end-start]), tail...)
+		end = start
+
+		lines := bytes.Split(bytes.TrimRight(tail, "\r\n"), []byte{'\n'})
+
+		// the first line is only complete once the start of the file was read
+		if end > 0 {
+			lines = lines[1:]
+		}
+
+		count, done, err := countLastTimestamp(lines)
 		if err != nil {
-			return fmt.Errorf("%w: %w", ErrFailedToOpenPreviousLogFile, err)
+			return 0, err
 		}
 
-		defer oldLogFile.Close()
+		if done || end == 0 {
+			return count, nil
+		}
+	}
+
+	return 0, nil
+}
 
-		newLogFile, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+// countLastTimestamp counts the lines at the end that share the timestamp of the last line,
+// done reports if a line with another timestamp was reached before them
+func countLastTimestamp(lines [][]byte) (int, bool, error) {
+	count := 0
+	lastTimestamp := time.Time{}
+
+	for i := len(lines) - 1; i >= 0; i-- {
+		if len(bytes.TrimSpace(lines[i])) == 0 {
+			continue
+		}
+
+		logLine := LogLine{}
+
+		if err := json.Unmarshal(lines[i], &logLine); err != nil {
+			return 0, false, fmt.Errorf("%w: %w", ErrFailedToParseLogLine, err)
+		}
+
+		if count > 0 && !logLine.Timestamp.Equal(lastTimestamp) {
+			return count, true, nil
+		}
+
+		lastTimestamp = logLine.Timestamp
+		count++
+	}
+
+	return count, false, nil
+}
+
+// countLastTimestampLinesCompressed returns the number of logs at the end of the compressed file that share the timestamp of the last log
+func countLastTimestampLinesCompressed(filename string) (int, error) {
+	file, err := compression.Open(filename)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrFailedToOpenLogFile, err)
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	count := 0
+	lastTimestamp := time.Time{}
+
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		logLine := LogLine{}
+
+		if err := json.Unmarshal(scanner.Bytes(), &logLine); err != nil {
+			return 0, fmt.Errorf("%w: %w", ErrFailedToParseLogLine, err)
+		}
+
+		if !logLine.Timestamp.Equal(lastTimestamp) {
+			count = 0
+		}
+
+		lastTimestamp = logLine.Timestamp
+		count++
+	}
+
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrFailedToReadFile, err)
+	}
+
+	return count, nil
+}
+
+// CountTimestampLines returns the number of lines in the file that have the timestamp, the lines are ordered oldest first
+func CountTimestampLines(filename string, timestamp time.Time) (int, error) {
+	file, err := compression.Open(filename)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrFailedToOpenLogFile, err)
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	count := 0
+
+	for scanner.Scan() {
+		logLine := LogLine{}
+
+		if err := json.Unmarshal(scanner.Bytes(), &logLine); err != nil {
+			return 0, fmt.Errorf("%w: %w", ErrFailedToParseLogLine, err)
+		}
+
+		if logLine.Timestamp.After(timestamp) {
+			break
+		}
+
+		if logLine.Timestamp.Equal(timestamp) {
+			count++
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrFailedToReadFile, err)
+	}
+
+	return count, nil
+}
+
+// CountLines returns the number of lines in the file
+func CountLines(filename string) (int64, error) {
+	file, err := compression.Open(filename)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrFailedToOpenLogFile, err)
+	}
+
+	defer file.Close()
+
+	lines := int64(0)
+	chunk := make([]byte, 64*1024)
+
+	for {
+		n, err := file.Read(chunk)
+
+		lines += int64(bytes.Count(chunk[:n], []byte{'\n'}))
+
+		if errors.Is(err, io.EOF) {
+			return lines, nil
+		}
+
+		if err != nil {
+			return 0, fmt.Errorf("%w: %w", ErrFailedToReadFile, err)
+		}
+	}
+}
+
+// ReadLastLineTimestamp returns the timestamp of the last log in the file, the file is read backwards
+// from the end so the newest log of a large archive can be found without reading all of it.
+// A compressed file can't be read backwards, so all of it is read
+func ReadLastLineTimestamp(filename string) (time.Time, error) {
+	codec, err := compression.Detect(filename)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %w", ErrFailedToOpenLogFile, err)
+	}
+
+	if codec != compression.CodecNone {
+		return readLastLineTimestampCompressed(filename)
+	}
+
+	file, err := os.OpenFile(filename, os.O_RDONLY, 0644)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %w", ErrFailedToOpenLogFile, err)
+	}
+
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %w", ErrFailedToReadFile, err)
+	}
+
+	end := info.Size()
+	tail := []byte{}
+	chunk := make([]byte, 64*1024)
+
+	for end > 0 {
+		start := max(end-int64(len(chunk)), 0)
+
+		if _, err := file.ReadAt(chunk[:end-start], start); err != nil {
+			return time.Time{}, fmt.Errorf("%w: %w", ErrFailedToReadFile, err)
+		}
+
+		tail = append(slices.Clone(chunk[:end-start]), tail...)
+		end = start
+
+		// the file ends with a newline, so only a newline before the last line means the whole line was read
+		if bytes.IndexByte(bytes.TrimRight(tail, "\r\n"), '\n') >= 0 {
+			break
+		}
+	}
+
+	lines := bytes.Split(bytes.TrimRight(tail, "\r\n"), []byte{'\n'})
+	line := lines[len(lines)-1]
+
+	if len(line) == 0 {
+		return time.Time{}, nil
+	}
+
+	logLine := LogLine{}
+
+	if err := json.Unmarshal(line, &logLine); err != nil {
+		return time.Time{}, fmt.Errorf("%w: %w", ErrFailedToParseLogLine, err)
+	}
+
+	return logLine.Timestamp, nil
+}
+
+// readLastLineTimestampCompressed returns the timestamp of the last log in the compressed file
+func readLastLineTimestampCompressed(filename string) (time.Time, error) {
+	file, err := compression.Open(filename)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %w", ErrFailedToOpenLogFile, err)
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	line := []byte{}
+
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) > 0 {
+			line = slices.Clone(scanner.Bytes())
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return time.Time{}, fmt.Errorf("%w: %w", ErrFailedToReadFile, err)
+	}
+
+	if len(line) == 0 {
+		return time.Time{}, nil
+	}
+
+	logLine := LogLine{}
+
+	if err := json.Unmarshal(line, &logLine); err != nil {
+		return time.Time{}, fmt.Errorf("%w: %w", ErrFailedToParseLogLine, err)
+	}
+
+	return logLine.Timestamp, nil
+}
+
+// FinalLogWrite combines the chunk files into the log file, when resuming the previous log file is added after them
+// without the logs the deduplicator has already seen. The new log file is built next to the old one and only replaces it
+// once it is completely written and synced to disk, so a failure at any point leaves the old log file as it was.
+// The log file is compressed with the codec its name ends in, the previous log file is read however it was compressed.
+// It returns the summary of the log file that was written
+func FinalLogWrite(filename string, tmpPath string, useResume bool, deduplicator *Deduplicator) (Summary, error) {
+	// Create directory path of the log file if it doesn't exist
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return Summary{}, fmt.Errorf("failed to create directory path: %w", err)
+	}
+
+	partialFilename := partialFileName(filename)
+
+	partialFile, err := os.OpenFile(partialFilename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return Summary{}, fmt.Errorf("%w: %w", ErrFailedToCreateOutputFile, err)
+	}
+
+	// the partial log file is never left behind, once it was renamed there is nothing left to remove
+	defer func() {
+		partialFile.Close()
+		os.Remove(partialFilename)
+	}()
+
+	writer, err := compression.NewWriter(partialFile, compression.ForFile(filename))
+	if err != nil {
+		return Summary{}, fmt.Errorf("%w: %w", ErrFailedToCreateOutputFile, err)
+	}
+
+	summaryWriter := NewSummaryWriter(writer)
+
+	if err := CombineLogFiles(tmpPath, summaryWriter, true); err != nil {
+		return Summary{}, fmt.Errorf("%w: %w", ErrFailedToCombineLogs, err)
+	}
+
+	if useResume {
+		previousLogFile, err := compression.Open(filename)
 		if err != nil {
-			return fmt.Errorf("%w: %w", ErrFailedToOpenNewLogFile, err)
+			return Summary{}, fmt.Errorf("%w: %w", ErrFailedToOpenPreviousLogFile, err)
 		}
 
-		defer newLogFile.Close()
+		defer previousLogFile.Close()
 
-		if _, err := io.Copy(newLogFile, oldLogFile); err != nil {
-			return fmt.Errorf("%w: %w", ErrFailedToCopyPreviousLogFile, err)
+		if err := copyLogLines(summaryWriter, previousLogFile, deduplicator); err != nil {
+			return Summary{}, fmt.Errorf("%w: %w", ErrFailedToCopyPreviousLogFile, err)
 		}
+	}
+
+	// the end of the compressed stream has to be written before the partial log file is synced
+	if err := writer.Close(); err != nil {
+		return Summary{}, fmt.Errorf("%w: %w", ErrFailedToCombineLogs, err)
+	}
+
+	if err := replaceFile(partialFile, filename); err != nil {
+		return Summary{}, err
+	}
+
+	return summaryWriter.Summary()
+}
+
+// replaceFile syncs the partial file to disk and renames it over the file, the directory is synced too so the rename survives a crash
+func replaceFile(partialFile *os.File, filename string) error {
+	if err := partialFile.Sync(); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToSyncLogFile, err)
+	}
+
+	if err := partialFile.Close(); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToSyncLogFile, err)
+	}
+
+	if err := os.Rename(partialFile.Name(), filename); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToRenameLogFile, err)
+	}
+
+	return SyncDir(filepath.Dir(filename))
+}
+
+// SyncDir syncs the entries of the directory to disk, windows can't sync a directory and journals its renames on its own
+func SyncDir(path string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	dir, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToSyncLogFile, err)
+	}
+
+	defer dir.Close()
+
+	if err := dir.Sync(); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToSyncLogFile, err)
+	}
 
-		oldLogFile.Close()
+	return nil
+}
 
-		if err := os.Remove(("previous_" + filename)); err != nil {
-			return fmt.Errorf("%w: %w", ErrFailedToRemovePreviousLogFile, err)
+// partialFileName is the log file that is being built next to the log file it replaces
+func partialFileName(filename string) string {
+	return filename + ".partial"
+}
+
+// RecoverLogFile puts the last complete log file back after a final write was cut off, returning true when it had to.
+// Older versions moved the log file aside to previous_<name> while they wrote the new one, which makes that one the last complete log file,
+// and a partial log file is never complete
+func RecoverLogFile(filename string) (bool, error) {
+	recovered := false
+
+	previousFilename := filepath.Join(filepath.Dir(filename), ("previous_" + filepath.Base(filename)))
+
+	if _, err := os.Stat(previousFilename); err == nil {
+		if err := os.Rename(previousFilename, filename); err != nil {
+			return false, fmt.Errorf("%w: %w", ErrFailedToRenameLogFile, err)
 		}
+
+		if err := SyncDir(filepath.Dir(filename)); err != nil {
+			return false, err
+		}
+
+		recovered = true
+	}
+
+	err := os.Remove(partialFileName(filename))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return recovered, fmt.Errorf("%w: %w", ErrFailedToRemoveLogFile, err)
+	}
+
+	return (recovered || err == nil), nil
+}
+
+// copyLogLines copies the log lines of the reader to the writer, dropping the ones that were already written
+func copyLogLines(writer io.Writer, reader io.Reader, deduplicator *Deduplicator) error {
+	// the logs of the reader are only duplicates of the ones that were written before it
+	deduplicator.nextSource()
+
+	bufferedWriter := bufio.NewWriter(writer)
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		// the scanner reuses its buffer, and adding the id can grow the line in place
+		line := slices.Clone(scanner.Bytes())
+
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		line, err := deduplicator.Process(line)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrFailedToDeduplicateLogLine, err)
+		}
+
+		// the log was already written
+		if line == nil {
+			continue
+		}
+
+		if _, err := bufferedWriter.Write(append(line, '\n')); err != nil {
+			return fmt.Errorf("%w: %w", ErrFailedToWriteLogLine, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToReadFile, err)
+	}
+
+	return bufferedWriter.Flush()
+}
+
+// FinalLogAppend appends the downloaded logs to the end of the existing log file, used when catching up on logs that are newer than the file.
+// The appended logs are synced to disk, and a failure cuts the log file back to where it ended so it never ends with part of them.
+// A compressed log file gets them as a new gzip member or zstd frame, which is read as part of the same stream.
+// It returns the summary of the logs that were appended
+func FinalLogAppend(filename string, tmpPath string) (Summary, error) {
+	logFile, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return Summary{}, fmt.Errorf("%w: %w", ErrFailedToCreateOutputFile, err)
+	}
+
+	defer logFile.Close()
+
+	stat, err := logFile.Stat()
+	if err != nil {
+		return Summary{}, fmt.Errorf("%w: %w", ErrFailedToOpenLogFile, err)
+	}
+
+	writer, err := compression.NewWriter(logFile, compression.ForFile(filename))
+	if err != nil {
+		return Summary{}, fmt.Errorf("%w: %w", ErrFailedToCreateOutputFile, err)
+	}
+
+	summaryWriter := NewSummaryWriter(writer)
+
+	err = CombineLogFiles(tmpPath, summaryWriter, false)
+	if err == nil {
+		err = writer.Close()
+	}
+
+	if err == nil {
+		err = logFile.Sync()
+	}
+
+	if err != nil {
+		logFile.Truncate(stat.Size())
+
+		return Summary{}, fmt.Errorf("%w: %w", ErrFailedToCombineLogs, err)
+	}
+
+	return summaryWriter.Summary()
+}
+
+// TruncateLogFile cuts the log file back to the size it had, used to undo the logs a catch up appended before it was cut off
+func TruncateLogFile(filename string, size int64) error {
+	if err := os.Truncate(filename, size); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToTruncateLogFile, err)
 	}
 
 	return nil
 }
 
-func ClearTempLogFiles() error {
-	files, err := filepath.Glob(filepath.Join(TMP_PATH, "*.jsonl"))
+// ClearTempLogFiles removes the chunk files in the tmp path except for the ones to keep, the tmp paths of other targets inside it are left alone
+func ClearTempLogFiles(tmpPath string, keep ...string) error {
+	files, err := chunkFiles(tmpPath)
 	if err != nil {
 		return fmt.Errorf("%w: %w", ErrFailedToGlobLogFiles, err)
 	}
 
 	for _, file := range files {
-		if err := os.Remove(file); err != nil {
+		if slices.Contains(keep, file) {
+			continue
+		}
+
+		if err := os.Remove(file); err != nil && !errors.Is(err, fs.ErrNotExist) {
 			return fmt.Errorf("%w: %w", ErrFailedToRemoveLogFile, err)
 		}
 	}
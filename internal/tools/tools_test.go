@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCountLastTimestampLines(t *testing.T) {
+	lines := [][]byte{}
+
+	// enough logs before the last timestamp that the file is read backwards in more than one chunk
+	for i := range 3000 {
+		lines = append(lines, dedupeLine(i%50, "older"))
+	}
+
+	for range 1500 {
+		lines = append(lines, dedupeLine(59, "newest"))
+	}
+
+	content := append(bytes.Join(lines, []byte{'\n'}), '\n')
+
+	plain := filepath.Join(t.TempDir(), "logs.jsonl")
+
+	if err := os.WriteFile(plain, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	compressed := bytes.Buffer{}
+	writer := gzip.NewWriter(&compressed)
+	writer.Write(content)
+	writer.Close()
+
+	gzipped := filepath.Join(t.TempDir(), "logs.jsonl.gz")
+
+	if err := os.WriteFile(gzipped, compressed.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, filename := range []string{plain, gzipped} {
+		count, err := CountLastTimestampLines(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if count != 1500 {
+			t.Fatalf("expected 1500 logs at the last timestamp of %s, got %d", filepath.Base(filename), count)
+		}
+	}
+}
+
+func TestWriteLogsCountsTheWrittenEdges(t *testing.T) {
+	chunkFile := filepath.Join(t.TempDir(), "chunk.jsonl")
+
+	if err := os.WriteFile(chunkFile, bytes.Join([][]byte{dedupeLine(5, "edge"), dedupeLine(5, "other")}, []byte{'\n'}), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	deduplicator := NewDeduplicator(true, false)
+
+	if err := deduplicator.Seed(chunkFile); err != nil {
+		t.Fatal(err)
+	}
+
+	// the page starts at the oldest timestamp of the chunk file again, only the new log at it is written
+	page := [][]byte{dedupeLine(2, "oldest"), dedupeLine(2, "oldest"), dedupeLine(3, "middle"), dedupeLine(5, "edge"), dedupeLine(5, "new")}
+
+	flushed, err := writeLogs(io.Discard, page, func(line []byte) ([]byte, error) { return line, nil }, deduplicator)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Flushed{
+		Logs:     4,
+		Oldest:   time.Date(2024, 1, 1, 0, 0, 2, 0, time.UTC),
+		AtOldest: 2,
+		Newest:   time.Date(2024, 1, 1, 0, 0, 5, 0, time.UTC),
+		AtNewest: 1,
+	}
+
+	if flushed != want {
+		t.Fatalf("expected %+v, got %+v", want, flushed)
+	}
+}
@@ -0,0 +1,11 @@
+package workdir
+
+import "errors"
+
+var (
+	ErrFailedToCreateWorkDir = errors.New("failed to create work directory")
+	ErrFailedToLockWorkDir   = errors.New("failed to lock work directory")
+	ErrWorkDirLocked         = errors.New("another download is writing this log file")
+	ErrFailedToRemoveWorkDir = errors.New("failed to remove work directory")
+	ErrFailedToReadWorkDirs  = errors.New("failed to read work directories")
+)
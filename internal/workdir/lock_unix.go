@@ -0,0 +1,25 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package workdir
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// the lock file is unlinked while it is still locked, so a run that opened it before can tell it was removed
+const removeWhileLocked = true
+
+func lockFile(file *os.File) error {
+	err := syscall.Flock(int(file.Fd()), (syscall.LOCK_EX | syscall.LOCK_NB))
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return errLocked
+	}
+
+	return err
+}
+
+func unlockFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
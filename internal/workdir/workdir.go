@@ -0,0 +1,237 @@
+package workdir
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LOCK_FILE_NAME marks a directory as a work directory, it is locked by the run that downloads into it
+var LOCK_FILE_NAME = "download.lock"
+
+var errLocked = errors.New("locked")
+
+// unsafeNameCharactersRe matches everything that shouldn't end up in the name of a work directory
+var unsafeNameCharactersRe = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// WorkDir is the directory the chunk files and the checkpoint journal of a single log file are kept in while it is downloaded,
+// it stays locked while a run holds it so two runs can't download into the same log file
+type WorkDir struct {
+	Path string
+
+	lock *os.File
+}
+
+// PathFor returns the work directory of the log file in the base path, the same log file always gets the same one
+// so an interrupted download can be found again, and log files with the same name in other directories get their own
+func PathFor(basePath string, logFileName string) (string, error) {
+	absoluteLogFileName, err := filepath.Abs(logFileName)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrFailedToCreateWorkDir, err)
+	}
+
+	hash := sha256.Sum256([]byte(absoluteLogFileName))
+
+	name := strings.TrimSuffix(filepath.Base(logFileName), filepath.Ext(logFileName))
+	name = strings.Trim(unsafeNameCharactersRe.ReplaceAllString(name, "-"), "-.")
+
+	return filepath.Join(basePath, fmt.Sprintf("%s-%s", name, hex.EncodeToString(hash[:6]))), nil
+}
+
+// Acquire creates and locks the work directory of the log file, it fails with ErrWorkDirLocked while another run holds it
+func Acquire(basePath string, logFileName string) (*WorkDir, error) {
+	path, err := PathFor(basePath, logFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrFailedToCreateWorkDir, err)
+		}
+
+		lock, err := tryLock(path)
+		if errors.Is(err, errLocked) {
+			return nil, lockedError(path)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		// the run that held it removed it just before it was locked, so create it again
+		if lock == nil {
+			continue
+		}
+
+		// the process that holds the lock is written to it for the runs that find it locked
+		if err := lock.Truncate(0); err == nil {
+			lock.WriteString(strconv.Itoa(os.Getpid()))
+		}
+
+		return &WorkDir{Path: path, lock: lock}, nil
+	}
+}
+
+// Release unlocks the work directory and leaves what is in it for the next run, releasing it again does nothing
+func (w *WorkDir) Release() error {
+	if w.lock == nil {
+		return nil
+	}
+
+	lock := w.lock
+	w.lock = nil
+
+	defer lock.Close()
+
+	if err := unlockFile(lock); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToLockWorkDir, err)
+	}
+
+	return nil
+}
+
+// Remove deletes the work directory and everything in it, and unlocks it
+func (w *WorkDir) Remove() error {
+	if removeWhileLocked {
+		err := os.RemoveAll(w.Path)
+
+		w.Release()
+
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrFailedToRemoveWorkDir, err)
+		}
+
+		return nil
+	}
+
+	entries, err := os.ReadDir(w.Path)
+	if err != nil {
+		w.Release()
+
+		return fmt.Errorf("%w: %w", ErrFailedToRemoveWorkDir, err)
+	}
+
+	for _, entry := range entries {
+		if entry.Name() == LOCK_FILE_NAME {
+			continue
+		}
+
+		if err := os.RemoveAll(filepath.Join(w.Path, entry.Name())); err != nil {
+			w.Release()
+
+			return fmt.Errorf("%w: %w", ErrFailedToRemoveWorkDir, err)
+		}
+	}
+
+	w.Release()
+
+	// a run that opened the lock file in the meantime keeps it, and with it the directory
+	os.Remove(filepath.Join(w.Path, LOCK_FILE_NAME))
+	os.Remove(w.Path)
+
+	return nil
+}
+
+// CleanStale removes the work directories in the base path that no run holds anymore, unless keep wants them kept,
+// directories without a lock file weren't created by a run and are left alone. It returns the number of directories removed
+func CleanStale(basePath string, keep func(path string) bool) (int, error) {
+	entries, err := os.ReadDir(basePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrFailedToReadWorkDirs, err)
+	}
+
+	removed := 0
+
+	for _, entry := range entries {
+		path := filepath.Join(basePath, entry.Name())
+
+		if !entry.IsDir() {
+			continue
+		}
+
+		if _, err := os.Stat(filepath.Join(path, LOCK_FILE_NAME)); err != nil {
+			continue
+		}
+
+		// held by a run, removed by one in the meantime or can't be locked at all
+		lock, err := tryLock(path)
+		if err != nil || lock == nil {
+			continue
+		}
+
+		workDir := &WorkDir{Path: path, lock: lock}
+
+		if keep(path) {
+			workDir.Release()
+			continue
+		}
+
+		if err := workDir.Remove(); err != nil {
+			return removed, err
+		}
+
+		removed++
+	}
+
+	return removed, nil
+}
+
+// tryLock locks the lock file of the directory without waiting, nil without an error means the lock file was removed
+// by the run that held it before it could be locked
+func tryLock(path string) (*os.File, error) {
+	lockFileName := filepath.Join(path, LOCK_FILE_NAME)
+
+	lock, err := os.OpenFile(lockFileName, (os.O_CREATE | os.O_RDWR), 0644)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToLockWorkDir, err)
+	}
+
+	if err := lockFile(lock); err != nil {
+		lock.Close()
+
+		if errors.Is(err, errLocked) {
+			return nil, errLocked
+		}
+
+		return nil, fmt.Errorf("%w: %w", ErrFailedToLockWorkDir, err)
+	}
+
+	locked, err := lock.Stat()
+	if err != nil {
+		lock.Close()
+
+		return nil, fmt.Errorf("%w: %w", ErrFailedToLockWorkDir, err)
+	}
+
+	// the lock file that was locked has to be the one that is still there
+	current, err := os.Stat(lockFileName)
+	if err != nil || !os.SameFile(locked, current) {
+		unlockFile(lock)
+		lock.Close()
+
+		return nil, nil
+	}
+
+	return lock, nil
+}
+
+// lockedError tells which process holds the work directory
+func lockedError(path string) error {
+	pid, err := os.ReadFile(filepath.Join(path, LOCK_FILE_NAME))
+	if err != nil || len(pid) == 0 {
+		return ErrWorkDirLocked
+	}
+
+	return fmt.Errorf("%w: held by process %s", ErrWorkDirLocked, strings.TrimSpace(string(pid)))
+}
@@ -9,143 +9,307 @@ import (
 	"syscall"
 	"time"
 
+	"main/internal/checkpoint"
 	"main/internal/config"
+	"main/internal/manifest"
 	"main/internal/railway"
-	"main/internal/tools"
 
 	"github.com/briandowns/spinner"
 	"github.com/dustin/go-humanize"
 )
 
-func init() {
-	if err := tools.ClearTempLogFiles(); err != nil {
-		fmt.Printf("Error clearing temp log files: %s\n", err)
-		os.Exit(1)
+func main() {
+	switch config.Command {
+	case config.CommandMockServer:
+		runMockServer()
+		return
+	case config.CommandCompact:
+		runCompact()
+		return
+	}
+
+	// The logs are the only thing written to stdout with --output -, everything else that is printed goes to stderr
+	if config.Railway.Output == config.OutputStdout {
+		console = os.Stderr
 	}
-}
 
-func main() {
 	// Create the railway client
-	railwayClient := railway.NewAuthedClient(config.Railway.AccountToken.String())
+	railwayClient := authenticate()
 
-	flagName, value := config.Railway.GetRequiredGroupValue("service_or_deployment")
+	// Turn the names into ids and fill in what a project token decides
+	resolveTarget(railwayClient)
 
-	// Create the log file name
-	logFileName := fmt.Sprintf("%s-%s.jsonl", flagName, value)
+	// Set up signal handling for Ctrl / Cmd + C
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	// A sync only downloads the logs its archives are missing
+	if config.Command == config.CommandSync {
+		syncLogs(railwayClient, sigChan)
+		return
+	}
+
+	// A project is downloaded into a directory tree with a file per environment and service
+	if config.Railway.ProjectWide() {
+		downloadProject(railwayClient, sigChan)
+		return
+	}
+
+	// Create the log file name and the parameters it is downloaded with
+	logFileName, query, environmentWide := singleTarget()
+
+	// Work directories of runs that are gone are cleaned up, unless their download can be continued
+	cleanStaleWorkDirs()
+
+	// Streamed logs are appended to the log file as they arrive
+	if config.Railway.Follow.Bool() {
+		followLogs(railwayClient, sigChan, logFileName, query)
+		return
+	}
+
+	// Only one run at a time can download into the log file, its chunk files are kept in a work directory of its own
+	workDir := lockLogFile(logFileName)
+
+	// A save of the log file that was cut off is undone before the log file is looked at
+	recoverCutOffSave(workDir.Path, logFileName)
 
 	// If the log file does not exist and the resume flag is provided, exit
 	if _, err := os.Stat(logFileName); err != nil && config.Railway.Resume.Bool() {
-		fmt.Println("Could not find a log file to resume from but the --resume flag was provided")
+		fmt.Fprintln(console, "Could not find a log file to resume from but the --resume flag was provided")
+		os.Exit(1)
+	}
+
+	// If the log file does not exist and the catch up flag is provided, exit
+	if _, err := os.Stat(logFileName); err != nil && config.Railway.CatchUp.Bool() {
+		fmt.Fprintln(console, "Could not find a log file to catch up on but the --catch-up flag was provided")
 		os.Exit(1)
 	}
 
 	// If the log file does not exist and the overwrite flag is provided, exit
 	if _, err := os.Stat(logFileName); err != nil && config.Railway.OverwriteFile.Bool() {
-		fmt.Println("Could not find a log file to resume from but the --overwrite flag was provided")
+		fmt.Fprintln(console, "Could not find a log file to resume from but the --overwrite flag was provided")
 		os.Exit(1)
 	}
 
 	// Check if the log file already exists to avoid overwriting
 	if _, err := os.Stat(logFileName); err == nil && config.Railway.OverwriteFile.Bool() && config.Railway.Resume.Bool() {
-		fmt.Printf("Log file %s already exists, delete or remove it to continue\n", logFileName)
-		fmt.Println("If you want to resume downloading logs from the oldest downloaded log, use the --resume flag")
-		fmt.Println("If you want to overwrite the existing log file, use the --overwrite flag")
+		fmt.Fprintf(console, "Log file %s already exists, delete or remove it to continue\n", logFileName)
+		fmt.Fprintln(console, "If you want to resume downloading logs from the oldest downloaded log, use the --resume flag")
+		fmt.Fprintln(console, "If you want to overwrite the existing log file, use the --overwrite flag")
 		os.Exit(1)
 	}
 
-	// Set up signal handling for Ctrl / Cmd + C
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	// Refuse to continue a log file that was downloaded with other parameters, the logs wouldn't match
+	previousStatus := manifest.StatusComplete
+
+	if config.Railway.Resume.Bool() || config.Railway.CatchUp.Bool() {
+		status, err := verifyManifest(logFileName, query)
+		if err != nil {
+			fmt.Fprintf(console, "Error: %s\n", err)
+			fmt.Fprintln(console, "Use the same parameters to continue it, or --overwrite to start over")
+			os.Exit(1)
+		}
 
-	// Create the channels to communicate with the goroutines
-	doneChannel := make(chan bool)
-	errorChannel := make(chan error)
-	logLinesChannel := make(chan railway.LogLinesResponse)
+		previousStatus = status
+	}
 
 	// Create context for cancellation
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// Create the resume from timestamp
 	resumeFromTimestamp := time.Time{}
+	resumeSavedAtTimestamp := 0
 
 	// If the resume flag is set, read the last downloaded log timestamp
 	if config.Railway.Resume.Bool() {
-		lastDownloadedLogTimestamp, err := tools.ReadFirstLineTimestamp(logFileName)
+		// the logs at the oldest timestamp that are already saved are skipped when that timestamp is fetched again
+		lastDownloadedLogTimestamp, savedAtTimestamp, err := readOldestLog(logFileName)
 		if err != nil {
-			fmt.Printf("Error reading first line timestamp: %s\n", err)
+			fmt.Fprintf(console, "Error reading first line timestamp: %s\n", err)
 			os.Exit(1)
 		}
 
 		resumeFromTimestamp = lastDownloadedLogTimestamp
+		resumeSavedAtTimestamp = savedAtTimestamp
+
+		fmt.Fprintf(console, "Resuming from %s\n", formatPosition(resumeFromTimestamp))
+	}
+
+	// Create the catch up from timestamp
+	catchUpFromTimestamp := time.Time{}
+	catchUpSavedAtTimestamp := 0
+
+	// If the catch up flag is set, read the newest downloaded log timestamp
+	if config.Railway.CatchUp.Bool() {
+		// the logs at the newest timestamp that are already saved are skipped when that timestamp is fetched again
+		newestDownloadedLogTimestamp, savedAtTimestamp, err := readNewestLog(logFileName)
+		if err != nil {
+			fmt.Fprintf(console, "Error reading last line timestamp: %s\n", err)
+			os.Exit(1)
+		}
+
+		if newestDownloadedLogTimestamp.IsZero() {
+			fmt.Fprintf(console, "Log file %s has no logs to catch up from\n", logFileName)
+			os.Exit(1)
+		}
+
+		catchUpFromTimestamp = newestDownloadedLogTimestamp
+		catchUpSavedAtTimestamp = savedAtTimestamp
+
+		fmt.Fprintf(console, "Catching up from %s\n", formatPosition(catchUpFromTimestamp))
+	}
+
+	// Let the user know which part of the logs is downloaded when a time range is set
+	if since, until := config.Railway.Since.Time(), config.Railway.Until.Time(); !since.IsZero() || !until.IsZero() {
+		fmt.Fprintf(console, "Downloading logs %s\n", formatTimeRange(since, until))
+	}
+
+	target := logTarget{
+		logFileName: logFileName,
+		tmpPath:     workDir.Path,
+		workDir:     workDir,
+		getAllLogs:  singleTargetLogCollection(),
+		options: railway.GetLogsOptions{
+			ResumeFromTimestamp:     resumeFromTimestamp,
+			ResumeSavedAtTimestamp:  resumeSavedAtTimestamp,
+			CatchUpFromTimestamp:    catchUpFromTimestamp,
+			CatchUpSavedAtTimestamp: catchUpSavedAtTimestamp,
+			Since:                   config.Railway.Since.Time(),
+			Until:                   config.Railway.Until.Time(),
+			Shards:                  config.Railway.Shards.Int(),
+			RetryPolicy:             retryPolicy(),
+			DeploymentId:            config.Railway.DeploymentID.String(),
+			EnvironmentId:           config.Railway.EnvironmentID.String(),
+			ServiceId:               config.Railway.ServiceID.String(),
+			PluginId:                config.Railway.PluginID.String(),
+			Filter:                  config.Railway.Filter.String(),
+		},
+		// logs from every service end up in the same file, so keep track of where each one came from
+		withTags:     environmentWide,
+		deduplicator: newDeduplicator(),
+	}
+
+	// An interrupted download of the same log file is continued from its chunk files instead of starting over
+	mode := checkpointMode(config.Railway.Resume.Bool(), config.Railway.CatchUp.Bool())
+
+	interruptedDownload, err := loadCheckpoint(target.tmpPath, logFileName, query, mode)
+	if err != nil {
+		fmt.Fprintf(console, "Error: %s\n", err)
+		fmt.Fprintln(console, "Use --continue false to throw the interrupted download away and start over")
+		os.Exit(1)
+	}
+
+	if interruptedDownload != nil && !askToContinue([]string{describeCheckpoint(interruptedDownload)}) {
+		interruptedDownload = nil
+	}
+
+	if err := startCheckpoint(&target, query, mode, interruptedDownload); err != nil {
+		fmt.Fprintf(console, "Error starting checkpoint: %s\n", err)
+		os.Exit(1)
+	}
 
-		fmt.Printf("Resuming from %s\n", resumeFromTimestamp.UTC().Format("January 2, 2006 15:04:05 MST"))
+	if interruptedDownload != nil {
+		fmt.Fprintf(console, "Continuing the interrupted download with %s logs already downloaded\n", humanize.Comma(target.savedLogs))
 	}
 
-	// Create the spinner
-	logDownloadSpinner := spinner.New(spinner.CharSets[11], (100 * time.Millisecond))
-	logDownloadSpinner.Suffix = " 0 Logs"
+	// Create the spinner, it prints to stderr when the logs are written to stdout
+	logDownloadSpinner := spinner.New(spinner.CharSets[11], (100 * time.Millisecond), spinner.WithWriterFile(console))
+	logDownloadSpinner.Suffix = fmt.Sprintf(" %s Logs", humanize.Comma(target.savedLogs))
 	logDownloadSpinner.Reverse()
 	logDownloadSpinner.Start()
 
+	// Let the user know why the download stalls while the rate limit quota resets
+	railwayClient.OnRateLimit(func(resetAt time.Time) {
+		logDownloadSpinner.Lock()
+		defer logDownloadSpinner.Unlock()
+
+		logDownloadSpinner.Suffix = fmt.Sprintf(" Rate limited - Waiting until %s", formatPosition(resetAt))
+	})
+
 	// Initialize the variable to track the number of logs downloaded
 	downloadedLogs := int64(0)
 
-	go func() {
-		for logLines := range logLinesChannel {
-			if err := tools.FlushLogsToFile(logLines.Logs, fmt.Sprintf("./tmp/%d.jsonl", logLines.OldestLogTimestamp.UTC().UnixMilli())); err != nil {
-				errorChannel <- err
-				return
-			}
+	// Start the log collection goroutine
+	errorChannel := make(chan error, 1)
 
-			downloadedLogs += int64(len(logLines.Logs))
+	go func() {
+		var err error
 
+		downloadedLogs, err = collectLogs(ctx, railwayClient, target, func(collectedLogs int64, logLines railway.LogLinesResponse) {
 			logDownloadSpinner.Suffix = fmt.Sprintf(" %s Logs - Position: %s",
-				humanize.Comma(downloadedLogs),
-				logLines.OldestLogTimestamp.UTC().Format("January 2, 2006 15:04:05 MST"),
+				humanize.Comma(collectedLogs),
+				formatPosition(logLines.OldestLogTimestamp),
 			)
-		}
-	}()
+		})
 
-	// Start the log collection goroutine
-	railway.GetAllDeploymentLogsAsync(ctx, railwayClient, logLinesChannel, railway.GetLogsOptions{
-		ResumeFromTimestamp: resumeFromTimestamp,
-		DeploymentId:        config.Railway.DeploymentID.String(),
-		EnvironmentId:       config.Railway.EnvironmentID.String(),
-		ServiceId:           config.Railway.ServiceID.String(),
-		Filter:              config.Railway.Filter.String(),
-		ErrorChannel:        errorChannel,
-		DoneChannel:         doneChannel,
-	})
+		errorChannel <- err
+	}()
 
 	// Print the start message
-	fmt.Println("Collecting logs in the background... Press Ctrl / Cmd + C to stop and save logs")
+	fmt.Fprintln(console, "Collecting logs in the background... Press Ctrl / Cmd + C to stop and save logs")
 
 	// Wait for either Ctrl+C or background goroutine to finish
+	var collectErr error
+
+	interrupted := false
+
 	select {
 	case <-sigChan:
 		logDownloadSpinner.Stop()
 
-		fmt.Println("Received interrupt signal, stopping...")
+		fmt.Fprintln(console, "Received interrupt signal, stopping...")
 
 		cancel() // Cancel the context to stop the goroutine
-	case <-doneChannel:
-		logDownloadSpinner.Stop()
 
-		fmt.Println("Log collection completed")
-	case err := <-errorChannel:
+		interrupted = true
+
+		// Wait for the chunk that is being written to be flushed
+		collectErr = <-errorChannel
+	case collectErr = <-errorChannel:
 		logDownloadSpinner.Stop()
 
-		fmt.Printf("Error: %s\n", strings.TrimSpace(err.Error()))
+		if !collectionFinished(collectErr, downloadedLogs) {
+			fmt.Fprintf(console, "Error: %s\n", strings.TrimSpace(collectErr.Error()))
+		} else {
+			fmt.Fprintln(console, "Log collection completed")
+		}
+	}
+
+	status := downloadStatus(collectErr, interrupted, config.Railway.CatchUp.Bool(), previousStatus)
+
+	// the logs of a sharded download that stopped early are kept in the work directory until every window was downloaded
+	if target.journal.UnfinishedWindows() {
+		fmt.Fprintf(console, "Error: %s\n", checkpoint.ErrUnfinishedWindows)
+		fmt.Fprintln(console, "Run it again with --continue true to download the rest of the windows")
+		os.Exit(1)
 	}
 
 	// If no logs were collected, exit
 	if downloadedLogs == 0 {
-		fmt.Println("No logs collected, exiting...")
+		// a resumed log file that has nothing older left is complete now
+		if config.Railway.Resume.Bool() {
+			summary, err := logFileSummary(logFileName)
+			if err == nil {
+				err = saveManifest(logFileName, query, status, summary)
+			}
+
+			if err != nil {
+				fmt.Fprintf(console, "Error saving manifest: %s\n", err)
+				os.Exit(1)
+			}
+		}
+
+		if err := finishCheckpoint(target); err != nil {
+			fmt.Fprintf(console, "Error removing checkpoint: %s\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Fprintln(console, "No logs collected, exiting...")
 		os.Exit(0)
 	}
 
 	// Create the flush logs spinner
-	flushLogsSpinner := spinner.New(spinner.CharSets[11], (100 * time.Millisecond))
+	flushLogsSpinner := spinner.New(spinner.CharSets[11], (100 * time.Millisecond), spinner.WithWriterFile(console))
 	flushLogsSpinner.Suffix = " Flushing logs"
 	flushLogsSpinner.Reverse()
 
@@ -158,8 +322,21 @@ func main() {
 	// Flush logs to file before exiting
 	// This handles the reconstruction of the multiple *.jsonl files into a single log file
 	// if `useResume` is true, it will prepend the newly downloaded logs to the existing log file
-	if err := tools.FinalLogWrite(logFileName, config.Railway.Resume.Bool()); err != nil {
-		fmt.Printf("Error saving logs: %s\n", err)
+	// when catching up, the newly downloaded logs are appended to the existing log file instead
+	summary, err := saveLogs(target, config.Railway.Resume.Bool(), config.Railway.CatchUp.Bool())
+	if err != nil {
+		fmt.Fprintf(console, "Error saving logs: %s\n", err)
+		os.Exit(1)
+	}
+
+	if err := saveManifest(logFileName, query, status, summary); err != nil {
+		fmt.Fprintf(console, "Error saving manifest: %s\n", err)
+		os.Exit(1)
+	}
+
+	// The logs are saved, the interrupted download doesn't have to be continued anymore
+	if err := finishCheckpoint(target); err != nil {
+		fmt.Fprintf(console, "Error removing checkpoint: %s\n", err)
 		os.Exit(1)
 	}
 
@@ -168,9 +345,12 @@ func main() {
 	flushLogsSpinner.Stop()
 
 	// Print the completion message
-	if config.Railway.Resume.Bool() {
-		fmt.Printf("Flushed an additional %s logs to file: %s\n", humanize.Comma(downloadedLogs), logFileName)
-	} else {
-		fmt.Printf("Flushed %s logs to file: %s\n", humanize.Comma(downloadedLogs), logFileName)
+	switch {
+	case logFileName == config.OutputStdout:
+		fmt.Fprintf(console, "Wrote %s logs to stdout\n", humanize.Comma(downloadedLogs))
+	case config.Railway.Resume.Bool() || config.Railway.CatchUp.Bool():
+		fmt.Fprintf(console, "Flushed an additional %s logs to file: %s\n", humanize.Comma(downloadedLogs), logFileName)
+	default:
+		fmt.Fprintf(console, "Flushed %s logs to file: %s\n", humanize.Comma(downloadedLogs), logFileName)
 	}
 }
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"errors"
+	"runtime/debug"
+	"time"
+
+	"main/internal/archive"
+	"main/internal/config"
+	"main/internal/manifest"
+	"main/internal/railway"
+	"main/internal/tools"
+)
+
+// VERSION is the version of the log downloader that is written to the manifests, set it when building with -ldflags "-X main.VERSION=v1.2.3"
+var VERSION = ""
+
+// toolVersion returns the version of the log downloader, falling back to the module version or the commit it was built from
+func toolVersion() string {
+	if VERSION != "" {
+		return VERSION
+	}
+
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "dev"
+	}
+
+	if buildInfo.Main.Version != "" && buildInfo.Main.Version != "(devel)" {
+		return buildInfo.Main.Version
+	}
+
+	for _, setting := range buildInfo.Settings {
+		if setting.Key == "vcs.revision" && len(setting.Value) >= 12 {
+			return "dev-" + setting.Value[:12]
+		}
+	}
+
+	return "dev"
+}
+
+// downloadQuery describes the logs that are downloaded into a single log file from the config
+func downloadQuery(kind string, withTags bool) manifest.Query {
+	return manifest.Query{
+		Kind:          kind,
+		ProjectId:     config.Railway.ProjectID.String(),
+		EnvironmentId: config.Railway.EnvironmentID.String(),
+		ServiceId:     config.Railway.ServiceID.String(),
+		DeploymentId:  config.Railway.DeploymentID.String(),
+		PluginId:      config.Railway.PluginID.String(),
+		Filter:        config.Railway.Filter.String(),
+		Since:         optionalTime(config.Railway.Since.Time()),
+		Until:         optionalTime(config.Railway.Until.Time()),
+		WithTags:      withTags,
+	}
+}
+
+// verifyManifest refuses to continue a log file that was downloaded with other parameters,
+// returning the status of the log file, a log file without a manifest can always be continued and is assumed to be complete
+func verifyManifest(logFileName string, query manifest.Query) (manifest.Status, error) {
+	previous, err := manifest.Read(logFileName)
+	if err != nil {
+		return "", err
+	}
+
+	if previous == nil {
+		return manifest.StatusComplete, nil
+	}
+
+	if err := query.Verify(previous.Query); err != nil {
+		return "", err
+	}
+
+	return previous.Status, nil
+}
+
+// saveManifest records the parameters, time range, number of lines and status of the log file next to it,
+// the summary describes the log file as it was written so it doesn't have to be read again
+func saveManifest(logFileName string, query manifest.Query, status manifest.Status, summary tools.Summary) error {
+	// logs written to stdout have no log file to describe
+	if logFileName == config.OutputStdout {
+		return nil
+	}
+
+	return manifest.Write(logFileName, manifest.Manifest{
+		ToolVersion: toolVersion(),
+		Query:       query,
+		Coverage: manifest.Coverage{
+			Oldest: optionalTime(summary.Oldest),
+			Newest: optionalTime(summary.Newest),
+		},
+		Lines:     summary.Lines,
+		Status:    status,
+		UpdatedAt: time.Now().UTC(),
+	})
+}
+
+// logFileSummary returns what the log file holds from the index of an archive or the manifest of a log file,
+// only a log file without a manifest is read
+func logFileSummary(logFileName string) (tools.Summary, error) {
+	if !archive.Named(logFileName) {
+		previous, err := manifest.Read(logFileName)
+		if err != nil {
+			return tools.Summary{}, err
+		}
+
+		if previous != nil {
+			return tools.Summary{Lines: previous.Lines, Oldest: timeOf(previous.Coverage.Oldest), Newest: timeOf(previous.Coverage.Newest)}, nil
+		}
+	}
+
+	// a log file that doesn't exist yet holds nothing
+	if logFileSize(logFileName) == 0 {
+		return tools.Summary{}, nil
+	}
+
+	lines, err := countLogs(logFileName)
+	if err != nil {
+		return tools.Summary{}, err
+	}
+
+	oldest, _, err := readOldestLog(logFileName)
+	if err != nil {
+		return tools.Summary{}, err
+	}
+
+	newest, _, err := readNewestLog(logFileName)
+	if err != nil {
+		return tools.Summary{}, err
+	}
+
+	return tools.Summary{Lines: lines, Oldest: oldest, Newest: newest}, nil
+}
+
+// downloadStatus returns the status of a log file after a download, a download that stopped early leaves out the oldest logs,
+// catching up only adds newer logs so the log file keeps the status it had
+func downloadStatus(collectErr error, interrupted bool, catchUp bool, previousStatus manifest.Status) manifest.Status {
+	if catchUp {
+		return previousStatus
+	}
+
+	if interrupted || (collectErr != nil && !errors.Is(collectErr, railway.ErrNoLogsFound)) {
+		return manifest.StatusIncomplete
+	}
+
+	return manifest.StatusComplete
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	t = t.UTC()
+
+	return &t
+}
+
+func timeOf(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+
+	return *t
+}
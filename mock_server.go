@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"main/internal/config"
+	"main/internal/mockserver"
+)
+
+// runMockServer serves the fixtures until the program is stopped
+func runMockServer() {
+	server, err := mockserver.New(config.MockServer.Logs.String(), config.MockServer.HttpLogs.String())
+	if err != nil {
+		fmt.Fprintf(console, "Error loading fixtures: %s\n", err)
+		os.Exit(1)
+	}
+
+	logs, httpLogs := server.Stats()
+
+	mux := http.NewServeMux()
+	mux.Handle(mockserver.GRAPHQL_PATH, server)
+
+	fmt.Fprintf(console, "Serving %d logs and %d http logs\n", logs, httpLogs)
+	fmt.Fprintf(console, "Download them with --endpoint http://%s%s\n", config.MockServer.Address, mockserver.GRAPHQL_PATH)
+
+	if err := http.ListenAndServe(config.MockServer.Address.String(), mux); err != nil {
+		fmt.Fprintf(console, "Error running mock server: %s\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"main/internal/compression"
+	"main/internal/config"
+	"main/internal/tools"
+)
+
+// stdout is where the logs are written with --output -
+var stdout = os.Stdout
+
+// console is where everything but the logs is printed, stderr when the logs are written to stdout with --output -
+var console = os.Stdout
+
+// outputValues are what the placeholders of the --output template stand for in a single log file, empty when it isn't known
+type outputValues struct {
+	kind        string
+	project     string
+	environment string
+	service     string
+	deployment  string
+	plugin      string
+}
+
+// outputFileName fills in the --output template for the log file, the default name is used without one.
+// It exits when the template uses a placeholder that isn't known for the log file
+func outputFileName(defaultName string, values outputValues) string {
+	template := config.Railway.Output.String()
+
+	switch template {
+	case "":
+		return defaultName
+	case config.OutputStdout:
+		return config.OutputStdout
+	}
+
+	placeholders := map[string]string{
+		"kind":        values.kind,
+		"project":     values.project,
+		"environment": values.environment,
+		"service":     values.service,
+		"deployment":  values.deployment,
+		"plugin":      values.plugin,
+		"date":        config.StartedAt().UTC().Format("2006-01-02"),
+		"filter_hash": filterHash(config.Railway.Filter.String()),
+	}
+
+	unknown := ""
+
+	logFileName := config.ReplaceOutputPlaceholders(template, func(name string) string {
+		value := placeholders[name]
+
+		if value == "" && unknown == "" {
+			unknown = name
+		}
+
+		return sanitizePathName(value)
+	})
+
+	if unknown != "" {
+		fmt.Fprintf(console, "Error: the {%s} placeholder of --output isn't known for %s logs\n", unknown, values.kind)
+		os.Exit(1)
+	}
+
+	return logFileName
+}
+
+// requireUniqueOutputs exits when the --output template gives more than one target the same log file
+func requireUniqueOutputs(logFileNames []string) {
+	seen := map[string]bool{}
+
+	for _, logFileName := range logFileNames {
+		if seen[logFileName] {
+			fmt.Fprintf(console, "Error: --output saves more than one service to %s, add {environment} and {service} to the template\n", logFileName)
+			os.Exit(1)
+		}
+
+		seen[logFileName] = true
+	}
+}
+
+// filterHash returns a short hash of the filter, so log files downloaded with different filters get different names
+func filterHash(filter string) string {
+	hash := sha256.Sum256([]byte(filter))
+
+	return hex.EncodeToString(hash[:4])
+}
+
+// writeStdout combines the chunk files into stdout, compressed with --compress
+func writeStdout(tmpPath string, newestFirst bool) error {
+	writer, err := compression.NewWriter(stdout, config.Railway.Compress.Codec())
+	if err != nil {
+		return err
+	}
+
+	if err := tools.CombineLogFiles(tmpPath, writer, newestFirst); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
@@ -0,0 +1,480 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+
+	"main/internal/checkpoint"
+	"main/internal/config"
+	"main/internal/manifest"
+	"main/internal/railway"
+	"main/internal/workdir"
+
+	"github.com/briandowns/spinner"
+	"github.com/dustin/go-humanize"
+)
+
+// projectTargetResult is the outcome of downloading the logs of a single environment and service pair
+type projectTargetResult struct {
+	name        string
+	logFileName string
+
+	downloadedLogs int64
+
+	skipped bool
+	err     error
+}
+
+// unsafePathCharactersRe matches everything that shouldn't end up in a file or directory name
+var unsafePathCharactersRe = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// downloadProject downloads the logs of every service in every environment of the project into
+// a <project>/<environment>/<service>.jsonl directory tree, printing a summary of every target at the end
+func downloadProject(railwayClient *railway.RailwayClient, sigChan <-chan os.Signal) {
+	// Create context for cancellation
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	projectName, projectTargets := getProjectTargets(ctx, railwayClient)
+
+	// Work directories of runs that are gone are cleaned up, unless their download can be continued
+	cleanStaleWorkDirs()
+
+	// The interrupted downloads of the project are all continued or all started over
+	interruptedDownloads := []string{}
+
+	logFileNames := projectTargetLogFileNames(projectName, projectTargets)
+
+	for _, logFileName := range logFileNames {
+		workDirPath, err := workdir.PathFor(config.Railway.WorkDir.String(), logFileName)
+		if err != nil {
+			fmt.Fprintf(console, "Error: %s\n", err)
+			os.Exit(1)
+		}
+
+		interrupted, err := checkpoint.Load(workDirPath)
+		if err != nil {
+			fmt.Fprintf(console, "Error: %s\n", err)
+			os.Exit(1)
+		}
+
+		if interrupted != nil {
+			interruptedDownloads = append(interruptedDownloads, describeCheckpoint(interrupted))
+		}
+	}
+
+	continueDownloads := len(interruptedDownloads) > 0 && askToContinue(interruptedDownloads)
+
+	results := make([]projectTargetResult, len(projectTargets))
+
+	// Create the spinner
+	logDownloadSpinner := spinner.New(spinner.CharSets[11], (100 * time.Millisecond), spinner.WithWriterFile(console))
+	logDownloadSpinner.Suffix = " 0 Logs"
+	logDownloadSpinner.Reverse()
+	logDownloadSpinner.Start()
+
+	// Initialize the variables to track the progress across all targets
+	downloadedLogs := atomic.Int64{}
+	finishedTargets := atomic.Int64{}
+
+	// the spinner is updated from every goroutine, so hold its lock while changing the suffix
+	updateSpinner := func() {
+		logDownloadSpinner.Lock()
+		defer logDownloadSpinner.Unlock()
+
+		logDownloadSpinner.Suffix = fmt.Sprintf(" %s Logs - %d/%d services done",
+			humanize.Comma(downloadedLogs.Load()),
+			finishedTargets.Load(),
+			len(projectTargets),
+		)
+	}
+
+	// Let the user know why the downloads stall while the rate limit quota resets
+	railwayClient.OnRateLimit(func(resetAt time.Time) {
+		logDownloadSpinner.Lock()
+		defer logDownloadSpinner.Unlock()
+
+		logDownloadSpinner.Suffix = fmt.Sprintf(" %s Logs - Rate limited - Waiting until %s",
+			humanize.Comma(downloadedLogs.Load()),
+			formatPosition(resetAt),
+		)
+	})
+
+	// Start the log collection goroutines, at most `concurrency` targets are downloaded at the same time
+	semaphore := make(chan struct{}, config.Railway.Concurrency.Int())
+	waitGroup := sync.WaitGroup{}
+
+	for i, projectTarget := range projectTargets {
+		waitGroup.Add(1)
+
+		go func() {
+			defer waitGroup.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			results[i] = downloadProjectTarget(ctx, railwayClient, logFileNames[i], projectTarget, continueDownloads, func(count int) {
+				downloadedLogs.Add(int64(count))
+				updateSpinner()
+			})
+
+			finishedTargets.Add(1)
+			updateSpinner()
+		}()
+	}
+
+	doneChannel := make(chan struct{})
+
+	go func() {
+		waitGroup.Wait()
+		close(doneChannel)
+	}()
+
+	// Print the start message
+	fmt.Fprintf(console, "Collecting logs for %d services in the background... Press Ctrl / Cmd + C to stop and save logs\n", len(projectTargets))
+
+	// Wait for either Ctrl+C or all targets to finish
+	select {
+	case <-sigChan:
+		logDownloadSpinner.Stop()
+
+		fmt.Fprintln(console, "Received interrupt signal, stopping and saving logs...")
+
+		cancel() // Cancel the context to stop the goroutines
+
+		<-doneChannel
+	case <-doneChannel:
+		logDownloadSpinner.Stop()
+
+		fmt.Fprintln(console, "Log collection completed")
+	}
+
+	printProjectSummary(projectName, results)
+}
+
+// getProjectTargets returns the name of the project and every environment and service pair in it that the token can see,
+// exiting when there are none
+func getProjectTargets(ctx context.Context, railwayClient *railway.RailwayClient) (string, []railway.ProjectTarget) {
+	projectName, projectTargets, err := railway.GetProjectTargets(ctx, railwayClient, config.Railway.ProjectID.String())
+	if err != nil {
+		fmt.Fprintf(console, "Error: %s\n", strings.TrimSpace(err.Error()))
+		os.Exit(1)
+	}
+
+	// the environment is only set for a project token, which can't see the other environments of the project
+	if config.Railway.EnvironmentID != "" {
+		projectTargets = slices.DeleteFunc(projectTargets, func(projectTarget railway.ProjectTarget) bool {
+			return projectTarget.EnvironmentId != config.Railway.EnvironmentID.String()
+		})
+	}
+
+	if len(projectTargets) == 0 {
+		fmt.Fprintf(console, "Project %s has no services, exiting...\n", projectName)
+		os.Exit(0)
+	}
+
+	return projectName, projectTargets
+}
+
+// downloadProjectTarget downloads and saves the logs of a single environment and service pair,
+// an existing log file is resumed or overwritten depending on the flags and skipped otherwise
+func downloadProjectTarget(ctx context.Context, railwayClient *railway.RailwayClient, logFileName string, projectTarget railway.ProjectTarget, continueDownload bool, onFlush func(count int)) projectTargetResult {
+	result := projectTargetResult{
+		name:        fmt.Sprintf("%s/%s", projectTarget.EnvironmentName, projectTarget.ServiceName),
+		logFileName: logFileName,
+	}
+
+	// Create the resume and catch up from timestamps
+	resumeFromTimestamp := time.Time{}
+	resumeSavedAtTimestamp := 0
+	useResume := false
+
+	catchUpFromTimestamp := time.Time{}
+	catchUpSavedAtTimestamp := 0
+	getAllLogs := railway.GetAllDeploymentLogsBlocking
+
+	if config.Railway.Shards.Int() > 1 {
+		getAllLogs = railway.GetAllDeploymentLogsShardedBlocking
+	}
+
+	// the parameters the log file is downloaded with are recorded in its manifest
+	query := projectTargetQuery(projectTarget)
+
+	// only one run at a time can download into the log file, its chunk files are kept in a work directory of its own
+	workDir, err := workdir.Acquire(config.Railway.WorkDir.String(), result.logFileName)
+	if err != nil {
+		result.err = err
+		return result
+	}
+
+	// left for the next run when the logs couldn't be saved, a no-op once the work directory was removed
+	defer workDir.Release()
+
+	// a save of the log file that was cut off is undone before the log file is looked at
+	if _, err := recoverLogFile(workDir.Path, result.logFileName); err != nil {
+		result.err = err
+		return result
+	}
+
+	previousStatus := manifest.StatusComplete
+
+	if _, err := os.Stat(result.logFileName); err == nil {
+		// a log file that was downloaded with other parameters can't be continued
+		if config.Railway.CatchUp.Bool() || config.Railway.Resume.Bool() {
+			previousStatus, err = verifyManifest(result.logFileName, query)
+			if err != nil {
+				result.err = err
+				return result
+			}
+		}
+
+		switch {
+		case config.Railway.CatchUp.Bool():
+			newestDownloadedLogTimestamp, savedAtTimestamp, err := readNewestLog(result.logFileName)
+			if err != nil {
+				result.err = err
+				return result
+			}
+
+			// an empty file has nothing to catch up from, so download everything instead
+			if !newestDownloadedLogTimestamp.IsZero() {
+				catchUpFromTimestamp = newestDownloadedLogTimestamp
+				catchUpSavedAtTimestamp = savedAtTimestamp
+				getAllLogs = railway.GetAllDeploymentLogsCatchUpBlocking
+			}
+		case config.Railway.Resume.Bool():
+			lastDownloadedLogTimestamp, savedAtTimestamp, err := readOldestLog(result.logFileName)
+			if err != nil {
+				result.err = err
+				return result
+			}
+
+			resumeFromTimestamp = lastDownloadedLogTimestamp
+			resumeSavedAtTimestamp = savedAtTimestamp
+			useResume = true
+		case !config.Railway.OverwriteFile.Bool():
+			result.skipped = true
+			return result
+		}
+	}
+
+	target := logTarget{
+		logFileName: result.logFileName,
+		tmpPath:     workDir.Path,
+		workDir:     workDir,
+		getAllLogs:  getAllLogs,
+		options: railway.GetLogsOptions{
+			ResumeFromTimestamp:     resumeFromTimestamp,
+			ResumeSavedAtTimestamp:  resumeSavedAtTimestamp,
+			CatchUpFromTimestamp:    catchUpFromTimestamp,
+			CatchUpSavedAtTimestamp: catchUpSavedAtTimestamp,
+			Since:                   config.Railway.Since.Time(),
+			Until:                   config.Railway.Until.Time(),
+			Shards:                  config.Railway.Shards.Int(),
+			RetryPolicy:             retryPolicy(),
+			EnvironmentId:           projectTarget.EnvironmentId,
+			ServiceId:               projectTarget.ServiceId,
+			Filter:                  config.Railway.Filter.String(),
+		},
+		deduplicator: newDeduplicator(),
+	}
+
+	// an interrupted download of the target is continued from its chunk files
+	mode := checkpointMode(useResume, !catchUpFromTimestamp.IsZero())
+
+	interrupted, err := loadCheckpoint(target.tmpPath, result.logFileName, query, mode)
+	if err != nil {
+		result.err = err
+		return result
+	}
+
+	if !continueDownload {
+		interrupted = nil
+	}
+
+	if err := startCheckpoint(&target, query, mode, interrupted); err != nil {
+		result.err = err
+		return result
+	}
+
+	onFlush(int(target.savedLogs))
+
+	result.downloadedLogs, result.err = collectLogs(ctx, railwayClient, target, func(_ int64, logLines railway.LogLinesResponse) {
+		onFlush(len(logLines.Logs))
+	})
+
+	status := downloadStatus(result.err, ctx.Err() != nil, !catchUpFromTimestamp.IsZero(), previousStatus)
+
+	// the logs of a sharded download that stopped early are kept in the work directory until every window was downloaded
+	if target.journal.UnfinishedWindows() {
+		result.err = errors.Join(result.err, checkpoint.ErrUnfinishedWindows)
+
+		return result
+	}
+
+	if result.downloadedLogs == 0 {
+		// a resumed log file that has nothing older left is complete now
+		if useResume {
+			summary, err := logFileSummary(result.logFileName)
+			if err == nil {
+				err = saveManifest(result.logFileName, query, status, summary)
+			}
+
+			result.err = errors.Join(result.err, err)
+		}
+
+		result.err = errors.Join(result.err, finishCheckpoint(target))
+
+		return result
+	}
+
+	// caught up logs are newer than everything in the file, so they are appended instead
+	summary, err := saveLogs(target, useResume, !catchUpFromTimestamp.IsZero())
+	if err != nil {
+		result.err = errors.Join(result.err, err)
+
+		return result
+	}
+
+	if err := saveManifest(result.logFileName, query, status, summary); err != nil {
+		result.err = errors.Join(result.err, err)
+	}
+
+	// the logs are saved, the interrupted download doesn't have to be continued anymore
+	if err := finishCheckpoint(target); err != nil {
+		result.err = errors.Join(result.err, err)
+	}
+
+	return result
+}
+
+// projectTargetLogFileName returns the <project>/<environment>/<service>.jsonl log file of a target unless --output names it,
+// or the archive that takes its place
+func projectTargetLogFileName(projectName string, projectTarget railway.ProjectTarget) string {
+	defaultName := filepath.Join(
+		sanitizePathName(projectName),
+		sanitizePathName(projectTarget.EnvironmentName),
+		(sanitizePathName(projectTarget.ServiceName) + ".jsonl"),
+	)
+
+	return logFileNameFor(outputFileName(defaultName, outputValues{
+		kind:        "service",
+		project:     projectName,
+		environment: projectTarget.EnvironmentName,
+		service:     projectTarget.ServiceName,
+	}))
+}
+
+// projectTargetLogFileNames returns the log file of every target. Names that sanitize to the same path, like api/v2 and api-v2,
+// get the start of the service id added and then the start of the environment id, it exits when --output still gives
+// two of them the same log file
+func projectTargetLogFileNames(projectName string, projectTargets []railway.ProjectTarget) []string {
+	logFileNames := make([]string, len(projectTargets))
+
+	for i, projectTarget := range projectTargets {
+		logFileNames[i] = projectTargetLogFileName(projectName, projectTarget)
+	}
+
+	// the names are only changed for the log file, the summary keeps the real ones
+	projectTargets = slices.Clone(projectTargets)
+
+	// the same service has the same id in every environment, so only the environment id tells those apart
+	for _, disambiguate := range []func(projectTarget *railway.ProjectTarget){
+		func(projectTarget *railway.ProjectTarget) {
+			projectTarget.ServiceName = fmt.Sprintf("%s-%s", projectTarget.ServiceName, shortId(projectTarget.ServiceId))
+		},
+		func(projectTarget *railway.ProjectTarget) {
+			projectTarget.EnvironmentName = fmt.Sprintf("%s-%s", projectTarget.EnvironmentName, shortId(projectTarget.EnvironmentId))
+		},
+	} {
+		targetsPerLogFile := map[string]int{}
+
+		for _, logFileName := range logFileNames {
+			targetsPerLogFile[logFileName]++
+		}
+
+		for i := range projectTargets {
+			if targetsPerLogFile[logFileNames[i]] < 2 {
+				continue
+			}
+
+			disambiguate(&projectTargets[i])
+			logFileNames[i] = projectTargetLogFileName(projectName, projectTargets[i])
+		}
+	}
+
+	requireUniqueOutputs(logFileNames)
+
+	return logFileNames
+}
+
+// shortId returns the start of an id, enough to tell apart the few names that sanitize to the same path
+func shortId(id string) string {
+	return id[:min(len(id), 8)]
+}
+
+// projectTargetQuery returns the parameters the log file of a target is downloaded with
+func projectTargetQuery(projectTarget railway.ProjectTarget) manifest.Query {
+	return manifest.Query{
+		Kind:          "service",
+		ProjectId:     config.Railway.ProjectID.String(),
+		EnvironmentId: projectTarget.EnvironmentId,
+		ServiceId:     projectTarget.ServiceId,
+		Filter:        config.Railway.Filter.String(),
+		Since:         optionalTime(config.Railway.Since.Time()),
+		Until:         optionalTime(config.Railway.Until.Time()),
+	}
+}
+
+// printProjectSummary prints the outcome of every target of the project
+func printProjectSummary(projectName string, results []projectTargetResult) {
+	fmt.Fprintf(console, "\nSummary for project %s:\n", projectName)
+
+	summaryWriter := tabwriter.NewWriter(console, 0, 0, 2, ' ', 0)
+
+	totalLogs := int64(0)
+
+	for _, result := range results {
+		totalLogs += result.downloadedLogs
+
+		switch {
+		case result.skipped:
+			fmt.Fprintf(summaryWriter, "  %s\tskipped, %s already exists (use --resume, --catch-up or --overwrite)\n", result.name, result.logFileName)
+		case errors.Is(result.err, checkpoint.ErrUnfinishedWindows):
+			fmt.Fprintf(summaryWriter, "  %s\t%s logs downloaded, not saved to %s\terror: %s (use --continue true)\n", result.name, humanize.Comma(result.downloadedLogs), result.logFileName, strings.TrimSpace(result.err.Error()))
+		case errors.Is(result.err, railway.ErrNoLogsFound) && result.downloadedLogs == 0:
+			fmt.Fprintf(summaryWriter, "  %s\tno logs found\n", result.name)
+		case result.err != nil && !errors.Is(result.err, railway.ErrNoLogsFound):
+			fmt.Fprintf(summaryWriter, "  %s\t%s logs saved to %s\terror: %s\n", result.name, humanize.Comma(result.downloadedLogs), result.logFileName, strings.TrimSpace(result.err.Error()))
+		case result.downloadedLogs == 0:
+			fmt.Fprintf(summaryWriter, "  %s\tno logs collected\n", result.name)
+		default:
+			fmt.Fprintf(summaryWriter, "  %s\t%s logs saved to %s\n", result.name, humanize.Comma(result.downloadedLogs), result.logFileName)
+		}
+	}
+
+	summaryWriter.Flush()
+
+	fmt.Fprintf(console, "Flushed %s logs in total\n", humanize.Comma(totalLogs))
+}
+
+// sanitizePathName turns a project, environment or service name into something that is safe to use as a path element
+func sanitizePathName(name string) string {
+	sanitized := strings.Trim(unsafePathCharactersRe.ReplaceAllString(name, "-"), "-.")
+
+	if sanitized == "" {
+		return "unnamed"
+	}
+
+	return sanitized
+}
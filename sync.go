@@ -0,0 +1,323 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"main/internal/archive"
+	"main/internal/config"
+	"main/internal/manifest"
+	"main/internal/railway"
+	"main/internal/tools"
+	"main/internal/workdir"
+
+	"github.com/briandowns/spinner"
+	"github.com/dustin/go-humanize"
+)
+
+// syncTarget is an archive that the sync command keeps up to date and everything needed to download the logs that go into it
+type syncTarget struct {
+	logFileName string
+	query       manifest.Query
+
+	getAllLogs getAllLogsFunc
+	options    railway.GetLogsOptions
+
+	// keep the tags of every log, used when logs from multiple services end up in the same archive
+	withTags bool
+}
+
+// syncLogs downloads the logs that the archive of every target is missing, the newer logs since the last sync,
+// the older logs that are still kept and whatever an interrupted sync left out in between
+func syncLogs(railwayClient *railway.RailwayClient, sigChan <-chan os.Signal) {
+	// Create context for cancellation
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	targets := getSyncTargets(ctx, railwayClient)
+
+	// Work directories of runs that are gone are cleaned up, unless their download can be continued
+	cleanStaleWorkDirs()
+
+	// the gap that is downloaded stops on Ctrl / Cmd + C, the logs it downloaded so far are still saved
+	go func() {
+		select {
+		case <-sigChan:
+			fmt.Fprintln(console, "Received interrupt signal, stopping and saving logs...")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	failed := false
+
+	for _, target := range targets {
+		if ctx.Err() != nil {
+			break
+		}
+
+		if err := syncArchive(ctx, railwayClient, target); err != nil {
+			fmt.Fprintf(console, "Error syncing %s: %s\n", target.logFileName, strings.TrimSpace(err.Error()))
+			failed = true
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// getSyncTargets returns the archive of every environment and service pair of a project, or the archive of the single target
+func getSyncTargets(ctx context.Context, railwayClient *railway.RailwayClient) []syncTarget {
+	if !config.Railway.ProjectWide() {
+		logFileName, query, environmentWide := singleTarget()
+
+		return []syncTarget{{
+			logFileName: logFileName,
+			query:       query,
+			getAllLogs:  singleTargetLogCollection(),
+			options: railway.GetLogsOptions{
+				RetryPolicy:   retryPolicy(),
+				DeploymentId:  config.Railway.DeploymentID.String(),
+				EnvironmentId: config.Railway.EnvironmentID.String(),
+				ServiceId:     config.Railway.ServiceID.String(),
+				PluginId:      config.Railway.PluginID.String(),
+				Filter:        config.Railway.Filter.String(),
+			},
+			withTags: environmentWide,
+		}}
+	}
+
+	projectName, projectTargets := getProjectTargets(ctx, railwayClient)
+
+	logFileNames := projectTargetLogFileNames(projectName, projectTargets)
+
+	targets := make([]syncTarget, len(projectTargets))
+
+	for i, projectTarget := range projectTargets {
+		targets[i] = syncTarget{
+			logFileName: logFileNames[i],
+			query:       projectTargetQuery(projectTarget),
+			getAllLogs:  railway.GetAllDeploymentLogsBlocking,
+			options: railway.GetLogsOptions{
+				RetryPolicy:   retryPolicy(),
+				EnvironmentId: projectTarget.EnvironmentId,
+				ServiceId:     projectTarget.ServiceId,
+				Filter:        config.Railway.Filter.String(),
+			},
+		}
+	}
+
+	return targets
+}
+
+// syncArchive downloads the gaps in the coverage of the archive newest first, every gap is saved as a segment
+// together with the interval it covers so a sync that stops early only has to download what is still missing
+func syncArchive(ctx context.Context, railwayClient *railway.RailwayClient, target syncTarget) error {
+	// only one run at a time can add to the archive, the chunk files of a gap are kept in its work directory
+	workDir, err := workdir.Acquire(config.Railway.WorkDir.String(), target.logFileName)
+	if err != nil {
+		return err
+	}
+
+	// a gap that was cut off is downloaded again, so there is nothing to continue
+	defer workDir.Remove()
+
+	// a save of the archive that was cut off is undone before the archive is looked at
+	if _, err := recoverLogFile(workDir.Path, target.logFileName); err != nil {
+		return err
+	}
+
+	// an archive that was downloaded with other parameters can't be synced, the logs wouldn't match
+	previousStatus, err := verifyManifest(target.logFileName, target.query)
+	if err != nil {
+		return err
+	}
+
+	logArchive, err := openArchive(target.logFileName)
+	if err != nil {
+		return err
+	}
+
+	// an archive that was last saved by a download covers the logs from its oldest to its newest log,
+	// and every log before that when the download reached the oldest one
+	if len(logArchive.Index.Coverage) == 0 && len(logArchive.Index.Segments) > 0 {
+		oldest, _ := logArchive.Oldest()
+
+		covered := archive.Interval{Start: oldest, End: logArchive.Newest()}
+
+		if previousStatus == manifest.StatusComplete {
+			covered.Start = time.Time{}
+		}
+
+		logArchive.AssumeCovered(covered)
+	}
+
+	fmt.Fprintf(console, "Syncing %s, %s\n", target.logFileName, describeCoverage(logArchive.Index.Coverage))
+
+	// logs that come in while the archive is synced are left for the next sync
+	syncedUntil := time.Now().UTC()
+
+	gaps := logArchive.Gaps(syncedUntil)
+
+	// the newest logs are the ones a nightly sync is run for, the older ones are kept for a while longer
+	slices.Reverse(gaps)
+
+	syncedLogs := int64(0)
+
+	var syncErr error
+
+	for _, gap := range gaps {
+		downloadedLogs, err := syncGap(ctx, railwayClient, target, workDir.Path, logArchive, gap)
+		syncedLogs += downloadedLogs
+
+		if err != nil {
+			syncErr = err
+			break
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	status := manifest.StatusIncomplete
+
+	if logArchive.Complete() {
+		status = manifest.StatusComplete
+	}
+
+	summary, err := logFileSummary(target.logFileName)
+	if err == nil {
+		err = saveManifest(target.logFileName, target.query, status, summary)
+	}
+
+	if err != nil {
+		return errors.Join(syncErr, err)
+	}
+
+	fmt.Fprintf(console, "Synced %s new logs into %s, %s\n", humanize.Comma(syncedLogs), target.logFileName, describeCoverage(logArchive.Index.Coverage))
+
+	if missing := logArchive.Gaps(syncedUntil); len(missing) > 0 {
+		fmt.Fprintf(console, "Still missing %s, sync again to download them\n", describeGaps(missing))
+	}
+
+	return syncErr
+}
+
+// syncGap downloads the logs of the gap newest first and saves them as a segment of the archive. The whole gap is covered
+// once every log in it was downloaded, a download that stopped early only covers the gap down to the oldest log it got to
+func syncGap(ctx context.Context, railwayClient *railway.RailwayClient, target syncTarget, tmpPath string, logArchive *archive.Archive, gap archive.Interval) (int64, error) {
+	if err := tools.ClearTempLogFiles(tmpPath); err != nil {
+		return 0, err
+	}
+
+	// the logs at the end of the gap that are already saved are skipped when that timestamp is fetched
+	savedAtEnd, err := logArchive.LogsAt(gap.End)
+	if err != nil {
+		return 0, err
+	}
+
+	options := target.options
+	options.Since = gap.Start
+	options.Until = gap.End
+	options.ResumeFromTimestamp = gap.End
+	options.ResumeSavedAtTimestamp = savedAtEnd
+
+	gapTarget := logTarget{
+		logFileName:  target.logFileName,
+		tmpPath:      tmpPath,
+		getAllLogs:   target.getAllLogs,
+		options:      options,
+		withTags:     target.withTags,
+		deduplicator: newDeduplicator(),
+	}
+
+	fmt.Fprintf(console, "Downloading the logs %s\n", formatTimeRange(gap.Start, gap.End))
+
+	// Create the spinner
+	logDownloadSpinner := spinner.New(spinner.CharSets[11], (100 * time.Millisecond), spinner.WithWriterFile(console))
+	logDownloadSpinner.Suffix = " 0 Logs"
+	logDownloadSpinner.Reverse()
+	logDownloadSpinner.Start()
+
+	// Let the user know why the download stalls while the rate limit quota resets
+	railwayClient.OnRateLimit(func(resetAt time.Time) {
+		logDownloadSpinner.Lock()
+		defer logDownloadSpinner.Unlock()
+
+		logDownloadSpinner.Suffix = fmt.Sprintf(" Rate limited - Waiting until %s", formatPosition(resetAt))
+	})
+
+	// the oldest log that was downloaded, every log between it and the end of the gap was downloaded too
+	oldestDownloaded := time.Time{}
+
+	downloadedLogs, collectErr := collectLogs(ctx, railwayClient, gapTarget, func(collectedLogs int64, logLines railway.LogLinesResponse) {
+		oldestDownloaded = logLines.OldestLogTimestamp
+
+		logDownloadSpinner.Suffix = fmt.Sprintf(" %s Logs - Position: %s",
+			humanize.Comma(collectedLogs),
+			formatPosition(logLines.OldestLogTimestamp),
+		)
+	})
+
+	logDownloadSpinner.Stop()
+
+	// a gap without any logs is covered all the same
+	if errors.Is(collectErr, railway.ErrNoLogsFound) {
+		collectErr = nil
+	}
+
+	covered := gap
+
+	if ctx.Err() != nil || collectErr != nil {
+		// nothing of the gap was downloaded
+		if oldestDownloaded.IsZero() {
+			return 0, collectErr
+		}
+
+		covered.Start = oldestDownloaded
+	}
+
+	err = logArchive.SyncSegment(func(output io.Writer) error {
+		return tools.CombineLogFiles(tmpPath, output, true)
+	}, covered)
+
+	return downloadedLogs, errors.Join(collectErr, err)
+}
+
+// describeCoverage describes the time ranges an archive covers for the user
+func describeCoverage(coverage []archive.Interval) string {
+	if len(coverage) == 0 {
+		return "nothing synced yet"
+	}
+
+	intervals := make([]string, len(coverage))
+
+	for i, interval := range coverage {
+		if interval.FromOldest() {
+			intervals[i] = fmt.Sprintf("from the oldest log until %s", formatPosition(interval.End))
+		} else {
+			intervals[i] = fmt.Sprintf("from %s to %s", formatPosition(interval.Start), formatPosition(interval.End))
+		}
+	}
+
+	return fmt.Sprintf("covered %s", strings.Join(intervals, ", "))
+}
+
+// describeGaps describes the time ranges an archive is missing for the user
+func describeGaps(gaps []archive.Interval) string {
+	descriptions := make([]string, len(gaps))
+
+	for i, gap := range gaps {
+		descriptions[i] = formatTimeRange(gap.Start, gap.End)
+	}
+
+	return fmt.Sprintf("the logs %s", strings.Join(descriptions, ", "))
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"main/internal/config"
+	"main/internal/railway"
+)
+
+// resolveTarget turns the project, environment and service names into ids, and fills in the environment of a project token,
+// a project token can't see any other environment so its project is where the names are looked up
+func resolveTarget(railwayClient *railway.RailwayClient) {
+	ctx := context.Background()
+
+	tokenProjectId, tokenEnvironmentId := "", ""
+
+	if railwayClient.Credentials().Kind == railway.TokenKindProject {
+		var err error
+
+		tokenProjectId, tokenEnvironmentId, err = railway.GetProjectTokenScope(ctx, railwayClient)
+		if err != nil {
+			fmt.Fprintf(console, "Error: %s\n", strings.TrimSpace(err.Error()))
+			os.Exit(1)
+		}
+
+		fmt.Fprintf(console, "Using a project token for environment %s of project %s\n", tokenEnvironmentId, tokenProjectId)
+	}
+
+	project := config.Railway.ProjectID.String()
+	if project == "" {
+		project = tokenProjectId
+	}
+
+	resolvedTarget, err := railway.ResolveTarget(ctx, railwayClient, project, config.Railway.EnvironmentID.String(), config.Railway.ServiceID.String())
+	if err != nil {
+		fmt.Fprintf(console, "Error: %s\n", strings.TrimSpace(err.Error()))
+		os.Exit(1)
+	}
+
+	for _, reference := range []struct {
+		kind     string
+		value    *config.ConfigString
+		resolved string
+	}{
+		{"project", &config.Railway.ProjectID, resolvedTarget.ProjectId},
+		{"environment", &config.Railway.EnvironmentID, resolvedTarget.EnvironmentId},
+		{"service", &config.Railway.ServiceID, resolvedTarget.ServiceId},
+	} {
+		// the project is only kept when it was provided, it decides between downloading the project or a service
+		if *reference.value == "" {
+			continue
+		}
+
+		if reference.value.String() != reference.resolved {
+			fmt.Fprintf(console, "Found %s %s: %s\n", reference.kind, reference.value, reference.resolved)
+		}
+
+		*reference.value = config.ConfigString(reference.resolved)
+	}
+
+	if tokenProjectId == "" {
+		return
+	}
+
+	if config.Railway.ProjectID != "" && config.Railway.ProjectID.String() != tokenProjectId {
+		fmt.Fprintf(console, "The project token belongs to project %s, it can't download the logs of project %s\n", tokenProjectId, config.Railway.ProjectID)
+		os.Exit(1)
+	}
+
+	if config.Railway.EnvironmentID != "" && config.Railway.EnvironmentID.String() != tokenEnvironmentId {
+		fmt.Fprintf(console, "The project token belongs to environment %s, it can't download the logs of environment %s\n", tokenEnvironmentId, config.Railway.EnvironmentID)
+		os.Exit(1)
+	}
+
+	// the environment of a deployment is looked up from the deployment itself
+	if config.Railway.DeploymentID == "" {
+		config.Railway.EnvironmentID = config.ConfigString(tokenEnvironmentId)
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"main/internal/checkpoint"
+	"main/internal/config"
+	"main/internal/workdir"
+)
+
+// cleanStaleWorkDirs removes the work directories of runs that are gone, the ones with an interrupted download are kept to be continued
+func cleanStaleWorkDirs() {
+	if _, err := workdir.CleanStale(config.Railway.WorkDir.String(), checkpoint.Exists); err != nil {
+		fmt.Fprintf(console, "Error cleaning up work directories: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// lockLogFile locks the work directory of the log file, exiting when another run is writing the same log file
+func lockLogFile(logFileName string) *workdir.WorkDir {
+	workDir, err := workdir.Acquire(config.Railway.WorkDir.String(), logFileName)
+	if err != nil {
+		fmt.Fprintf(console, "Error: %s\n", err)
+		fmt.Fprintf(console, "Wait for it to finish, or stop it, before downloading %s again\n", logFileName)
+		os.Exit(1)
+	}
+
+	return workDir
+}
+
+// recoverCutOffSave undoes a save of the log file that was cut off, exiting when it can't
+func recoverCutOffSave(tmpPath string, logFileName string) {
+	recovered, err := recoverLogFile(tmpPath, logFileName)
+	if err != nil {
+		fmt.Fprintf(console, "Error recovering log file: %s\n", err)
+		os.Exit(1)
+	}
+
+	if recovered {
+		fmt.Fprintf(console, "Recovered %s from a save that was cut off\n", logFileName)
+	}
+}